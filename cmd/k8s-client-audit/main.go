@@ -1,30 +1,376 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"go/token"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
 	analyzers "github.com/amisstea/k8s-client-audit/internal/analyzers"
+	"github.com/amisstea/k8s-client-audit/internal/baseline"
+	"github.com/amisstea/k8s-client-audit/internal/config"
+	"github.com/amisstea/k8s-client-audit/internal/fix"
+	"github.com/amisstea/k8s-client-audit/internal/suppress"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/packages"
+)
 
-	"golang.org/x/tools/go/analysis/multichecker"
+// Exit codes. As in the x/tools analysis drivers, 1 means the run failed and
+// 3 or more that diagnostics were reported; the code grows with the highest
+// severity found.
+const (
+	exitOK          = 0
+	exitFailure     = 1
+	exitDiagnostics = 3
+	exitInfo        = exitDiagnostics
+	exitWarning     = 4
+	exitError       = 5
 )
 
+var severityExitCodes = map[analyzers.Severity]int{
+	analyzers.SeverityInfo:    exitInfo,
+	analyzers.SeverityWarning: exitWarning,
+	analyzers.SeverityError:   exitError,
+}
+
+func run(args []string) int {
+	fs := flag.NewFlagSet("k8s-client-audit", flag.ExitOnError)
+	configPath := fs.String("config", "", "path to the configuration file (default: "+config.FileName+" at the module root)")
+	experimental := fs.Bool("experimental", false, "enable all experimental analyzers")
+	enable := fs.String("enable", "", "comma-separated analyzers to run in addition to the defaults")
+	disable := fs.String("disable", "", "comma-separated analyzers to skip")
+	list := fs.Bool("list", false, "list the available analyzers and exit")
+	tests := fs.Bool("test", true, "indicates whether test files should be analyzed, too")
+	jsonOut := fs.Bool("json", false, "emit JSON output")
+	sarifOut := fs.Bool("sarif", false, "emit SARIF 2.1.0 output for code scanning tools")
+	minSeverity := fs.String("min-severity", string(analyzers.SeverityInfo), "report only diagnostics of at least this severity (info, warning, error)")
+	contextLines := fs.Int("c", -1, "display offending line with this many lines of context")
+	baselinePath := fs.String("baseline", "", "report only diagnostics not recorded in this baseline file")
+	writeBaseline := fs.String("write-baseline", "", "record the current diagnostics in this baseline file and exit")
+	applyFixes := fs.Bool("fix", false, "apply the suggested fixes of reported diagnostics")
+	checkSuppressions := fs.Bool("check-suppressions", false, "instead of diagnostics, report "+suppress.Prefix+" directives that lack a reason or no longer match a diagnostic")
+	registerAnalyzerFlags(fs, analyzers.Registry)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: k8s-client-audit [flags] packages...\n\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitFailure
+	}
+
+	if *list {
+		printRegistry(os.Stdout)
+		return exitOK
+	}
+
+	threshold, err := analyzers.ParseSeverity(*minSeverity)
+	if err != nil {
+		log.Print(err)
+		return exitFailure
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		log.Print(err)
+		return exitFailure
+	}
+	if err := configure(fs, args, cfg); err != nil {
+		log.Print(err)
+		return exitFailure
+	}
+
+	selected, err := analyzers.Select(analyzers.Registry, cfg.Selection().Override(analyzers.Selection{
+		Experimental: *experimental,
+		Enable:       splitList(*enable),
+		Disable:      splitList(*disable),
+	}))
+	if err != nil {
+		log.Print(err)
+		return exitFailure
+	}
+	if len(selected) == 0 {
+		log.Print("no analyzers selected")
+		return exitFailure
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitFailure
+	}
+
+	exitcode := exitOK
+	initial, err := load(fs.Args(), *tests, needFacts(selected))
+	if err != nil {
+		log.Print(err)
+		return exitFailure
+	}
+	// Report package errors but keep going; analyzers tolerate partial type info.
+	if n := packages.PrintErrors(initial); n > 0 {
+		exitcode = exitFailure
+	}
+
+	graph, err := checker.Analyze(selected, initial, nil)
+	if err != nil {
+		log.Print(err)
+		return exitFailure
+	}
+
+	problems := applySuppressions(graph, selected)
+	if *checkSuppressions {
+		for _, p := range problems {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", p.Position, p.Message)
+		}
+		if len(problems) > 0 {
+			exitcode = max(exitcode, exitDiagnostics)
+		}
+		return exitcode
+	}
+
+	if *writeBaseline != "" {
+		if err := recordBaseline(graph).Write(*writeBaseline); err != nil {
+			log.Print(err)
+			return exitFailure
+		}
+		return exitcode
+	}
+	if *baselinePath != "" {
+		b, err := baseline.Load(*baselinePath)
+		if err != nil {
+			log.Print(err)
+			return exitFailure
+		}
+		applyBaseline(graph, b)
+	}
+	dropDiagnostics(graph, func(act *checker.Action, d analysis.Diagnostic) bool {
+		return !analyzers.SeverityOf(act.Analyzer.Name).AtLeast(threshold)
+	})
+
+	if *applyFixes {
+		if err := writeFixes(graph); err != nil {
+			log.Print(err)
+			return exitFailure
+		}
+	}
+
+	switch {
+	case *sarifOut:
+		err = writeSARIF(os.Stdout, graph, selected)
+	case *jsonOut:
+		err = printJSON(os.Stdout, graph)
+	default:
+		err = printText(os.Stderr, graph, *contextLines)
+	}
+	if err != nil {
+		log.Print(err)
+		return exitFailure
+	}
+	for act := range graph.All() {
+		if act.Err != nil {
+			return exitFailure
+		}
+		if act.IsRoot && len(act.Diagnostics) > 0 {
+			exitcode = max(exitcode, severityExitCodes[analyzers.SeverityOf(act.Analyzer.Name)])
+		}
+	}
+	return exitcode
+}
+
+// dropDiagnostics removes the diagnostics of the root actions of graph for
+// which drop returns true.
+func dropDiagnostics(graph *checker.Graph, drop func(act *checker.Action, d analysis.Diagnostic) bool) {
+	for _, act := range graph.Roots {
+		act.Diagnostics = slices.DeleteFunc(act.Diagnostics, func(d analysis.Diagnostic) bool {
+			return drop(act, d)
+		})
+	}
+}
+
+// registerAnalyzerFlags adds the flags of each analyzer to fs, prefixed with
+// the analyzer name as the x/tools drivers do: -NAME.FLAG.
+func registerAnalyzerFlags(fs *flag.FlagSet, entries []analyzers.Entry) {
+	for _, e := range entries {
+		prefix := e.Name() + "."
+		e.Analyzer.Flags.VisitAll(func(f *flag.Flag) {
+			fs.Var(f.Value, prefix+f.Name, f.Usage)
+		})
+	}
+}
+
+// configure applies the thresholds and hot paths of cfg to the registry.
+// Analyzer flags given on the command line take precedence over the file, so
+// args are parsed again once it is applied.
+func configure(fs *flag.FlagSet, args []string, cfg *config.Config) error {
+	if err := cfg.Apply(analyzers.Registry); err != nil {
+		return err
+	}
+	return fs.Parse(args)
+}
+
+// applySuppressions removes diagnostics covered by suppression directives
+// from the root actions of graph and returns the problems found with the
+// directives themselves.
+func applySuppressions(graph *checker.Graph, selected []*analysis.Analyzer) []suppress.Problem {
+	ix := suppress.NewIndex()
+	for _, act := range graph.Roots {
+		for _, f := range act.Package.Syntax {
+			ix.AddFile(act.Package.Fset, f)
+		}
+	}
+	dropDiagnostics(graph, func(act *checker.Action, d analysis.Diagnostic) bool {
+		return ix.Suppresses(act.Analyzer.Name, act.Package.Fset.Position(d.Pos))
+	})
+
+	known := func(name string) bool {
+		_, ok := analyzers.Lookup(name)
+		return ok
+	}
+	ran := func(name string) bool {
+		return slices.ContainsFunc(selected, func(a *analysis.Analyzer) bool { return a.Name == name })
+	}
+	return ix.Problems(known, ran)
+}
+
+// writeFixes applies the suggested fixes of the reported diagnostics to the
+// files on disk. Fixes that conflict with another fix are reported and left
+// out; running again picks them up.
+func writeFixes(graph *checker.Graph) error {
+	var fset *token.FileSet
+	var diags []analysis.Diagnostic
+	forEachDiagnostic(graph, func(act *checker.Action, d analysis.Diagnostic) {
+		// All packages of a run share a file set.
+		fset = act.Package.Fset
+		diags = append(diags, d)
+	})
+	if fset == nil {
+		return nil
+	}
+	res, err := fix.Apply(fset, diags, os.ReadFile)
+	if err != nil {
+		return err
+	}
+	for name, content := range res.Files {
+		if err := os.WriteFile(name, content, 0o644); err != nil {
+			return err
+		}
+	}
+	for _, msg := range res.Skipped {
+		log.Printf("skipped conflicting fix: %s", msg)
+	}
+	return nil
+}
+
+// baselineKeyer computes baseline keys, caching file contents.
+type baselineKeyer map[string][]byte
+
+func (k baselineKeyer) key(act *checker.Action, d analysis.Diagnostic) baseline.Key {
+	filename := act.Package.Fset.Position(d.Pos).Filename
+	src, ok := k[filename]
+	if !ok {
+		src, _ = os.ReadFile(filename)
+		k[filename] = src
+	}
+	return baseline.KeyFor(act.Analyzer.Name, act.Package.PkgPath, act.Package.Fset, act.Package.Syntax, d.Pos, d.Message, src)
+}
+
+// recordBaseline returns a baseline holding the diagnostics of graph.
+func recordBaseline(graph *checker.Graph) *baseline.Baseline {
+	b := &baseline.Baseline{Version: baseline.Version}
+	keyer := baselineKeyer{}
+	for _, act := range graph.Roots {
+		for _, d := range act.Diagnostics {
+			b.Add(keyer.key(act, d), d.Message)
+		}
+	}
+	return b
+}
+
+// applyBaseline removes diagnostics recorded in b from graph.
+func applyBaseline(graph *checker.Graph, b *baseline.Baseline) {
+	m := b.Matcher()
+	keyer := baselineKeyer{}
+	dropDiagnostics(graph, func(act *checker.Action, d analysis.Diagnostic) bool {
+		return m.Match(keyer.key(act, d))
+	})
+}
+
+// loadConfig loads the configuration file at path or, if path is empty, the
+// one at the root of the current module. A missing file yields an empty config.
+func loadConfig(path string) (*config.Config, error) {
+	if path == "" {
+		found, err := config.Find(".")
+		if err != nil {
+			return nil, err
+		}
+		if found == "" {
+			return &config.Config{}, nil
+		}
+		path = found
+	}
+	return config.Load(path)
+}
+
+// load loads the packages matching patterns. Dependencies are loaded from
+// source only when some analyzer needs facts from them.
+func load(patterns []string, tests, allSyntax bool) ([]*packages.Package, error) {
+	mode := packages.LoadSyntax
+	if allSyntax {
+		mode = packages.LoadAllSyntax
+	}
+	conf := packages.Config{Mode: mode | packages.NeedModule, Tests: tests}
+	initial, err := packages.Load(&conf, patterns...)
+	if err == nil && len(initial) == 0 {
+		err = fmt.Errorf("%s matched no packages", strings.Join(patterns, " "))
+	}
+	return initial, err
+}
+
+// needFacts reports whether any of the analyzers, or their requirements,
+// produce or consume facts.
+func needFacts(as []*analysis.Analyzer) bool {
+	seen := map[*analysis.Analyzer]bool{}
+	var visit func([]*analysis.Analyzer) bool
+	visit = func(as []*analysis.Analyzer) bool {
+		for _, a := range as {
+			if seen[a] {
+				continue
+			}
+			seen[a] = true
+			if len(a.FactTypes) > 0 || visit(a.Requires) {
+				return true
+			}
+		}
+		return false
+	}
+	return visit(as)
+}
+
+func printRegistry(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tCATEGORY\tSEVERITY\tMATURITY\tDEFAULT\tDESCRIPTION")
+	for _, e := range analyzers.Registry {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%t\t%s\n", e.Name(), e.Category, e.Severity, e.Maturity, e.DefaultEnabled, e.Analyzer.Doc)
+	}
+	tw.Flush()
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
 func main() {
-	multichecker.Main(
-		analyzers.AnalyzerClientReuse,
-		analyzers.AnalyzerDiscoveryFlood,
-		analyzers.AnalyzerDynamicOveruse,
-		analyzers.AnalyzerLargePageSizes,
-		analyzers.AnalyzerLeakyWatch,
-		analyzers.AnalyzerListInLoop,
-		analyzers.AnalyzerManualPolling,
-		analyzers.AnalyzerMissingContext,
-		analyzers.AnalyzerMissingInformer,
-		analyzers.AnalyzerNoSelectors,
-		analyzers.AnalyzerQPSBurst,
-		analyzers.AnalyzerRESTMapperNotCached,
-		analyzers.AnalyzerRequeueBackoff,
-		analyzers.AnalyzerRestConfigDefaults,
-		analyzers.AnalyzerTightErrorLoops,
-		analyzers.AnalyzerUnboundedQueue,
-		analyzers.AnalyzerUnstructuredEverywhere,
-		analyzers.AnalyzerWideNamespace,
-	)
+	log.SetFlags(0)
+	log.SetPrefix("k8s-client-audit: ")
+	os.Exit(run(os.Args[1:]))
 }
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"testing"
+
+	analyzers "github.com/amisstea/k8s-client-audit/internal/analyzers"
+	"github.com/amisstea/k8s-client-audit/internal/config"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+func TestRegisterAnalyzerFlags_PrefixedByName(t *testing.T) {
+	a := &analysis.Analyzer{Name: "demo", Doc: "demo", Run: func(*analysis.Pass) (any, error) { return nil, nil }}
+	limit := a.Flags.Int("limit", 10, "limit")
+
+	fs := flag.NewFlagSet("k8s-client-audit", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	registerAnalyzerFlags(fs, []analyzers.Entry{{Analyzer: a}})
+
+	if err := fs.Parse([]string{"-demo.limit=5"}); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if *limit != 5 {
+		t.Errorf("expected -demo.limit to set the analyzer flag to 5, got %d", *limit)
+	}
+	if err := fs.Parse([]string{"-limit=5"}); err == nil {
+		t.Error("expected the unprefixed flag to be rejected")
+	}
+}
+
+func TestConfigure_CommandLineOverridesFile(t *testing.T) {
+	maxQPS := analyzers.AnalyzerQPSBurst.Flags.Lookup("max-qps")
+	maxBurst := analyzers.AnalyzerQPSBurst.Flags.Lookup("max-burst")
+	t.Cleanup(func() {
+		maxQPS.Value.Set(maxQPS.DefValue)
+		maxBurst.Value.Set(maxBurst.DefValue)
+	})
+
+	fs := flag.NewFlagSet("k8s-client-audit", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	registerAnalyzerFlags(fs, analyzers.Registry)
+	args := []string{"-qpsburst.max-qps=7", "./..."}
+	if err := fs.Parse(args); err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	cfg := &config.Config{Thresholds: map[string]map[string]string{
+		"qpsburst": {"max-qps": "100", "max-burst": "200"},
+	}}
+	if err := configure(fs, args, cfg); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+
+	if got := maxQPS.Value.String(); got != "7" {
+		t.Errorf("expected -qpsburst.max-qps to override the file, got max-qps %s", got)
+	}
+	if got := maxBurst.Value.String(); got != "200" {
+		t.Errorf("expected the file to set max-burst, got %s", got)
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+	"strings"
+
+	analyzers "github.com/amisstea/k8s-client-audit/internal/analyzers"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+)
+
+// The printers below follow the output of checker.Graph.PrintText and
+// PrintJSON, adding the severity and category of each diagnostic.
+
+// forEachDiagnostic calls f for every diagnostic of the root actions of graph.
+// Files shared by a package and its test variant are reported only once.
+func forEachDiagnostic(graph *checker.Graph, f func(act *checker.Action, d analysis.Diagnostic)) {
+	type key struct {
+		pos, end token.Position
+		analyzer string
+		message  string
+	}
+	seen := map[key]bool{}
+	for _, act := range graph.Roots {
+		fset := act.Package.Fset
+		for _, d := range act.Diagnostics {
+			k := key{fset.Position(d.Pos), fset.Position(d.End), act.Analyzer.Name, d.Message}
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+			f(act, d)
+		}
+	}
+}
+
+// printText writes diagnostics as "file:line:col: severity: message
+// [analyzer, category]", followed by contextLines lines of source around the
+// offending line when contextLines is not negative.
+func printText(w io.Writer, graph *checker.Graph, contextLines int) error {
+	var buf strings.Builder
+	for act := range graph.All() {
+		if act.Err != nil {
+			fmt.Fprintf(&buf, "%s: %v\n", act.Analyzer.Name, act.Err)
+		}
+	}
+	forEachDiagnostic(graph, func(act *checker.Action, d analysis.Diagnostic) {
+		fset := act.Package.Fset
+		e, _ := analyzers.Lookup(act.Analyzer.Name)
+		printPlain(&buf, fset, d.Pos, d.End, contextLines, fmt.Sprintf("%s: %s [%s, %s]",
+			analyzers.SeverityOf(act.Analyzer.Name), d.Message, act.Analyzer.Name, e.Category))
+		for _, rel := range d.Related {
+			printPlain(&buf, fset, rel.Pos, rel.End, contextLines, "\t"+rel.Message)
+		}
+	})
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
+
+func printPlain(w io.Writer, fset *token.FileSet, pos, end token.Pos, contextLines int, message string) {
+	posn := fset.Position(pos)
+	fmt.Fprintf(w, "%s: %s\n", posn, message)
+	if contextLines < 0 {
+		return
+	}
+	endPosn := fset.Position(end)
+	if !endPosn.IsValid() {
+		endPosn = posn
+	}
+	data, _ := os.ReadFile(posn.Filename)
+	lines := strings.Split(string(data), "\n")
+	for i := posn.Line - contextLines; i <= endPosn.Line+contextLines; i++ {
+		if 1 <= i && i <= len(lines) {
+			fmt.Fprintf(w, "%d\t%s\n", i, lines[i-1])
+		}
+	}
+}
+
+// jsonDiagnostic is the JSON form of a diagnostic. It extends the shape
+// written by the x/tools drivers with severity and analyzer category.
+type jsonDiagnostic struct {
+	Severity       analyzers.Severity `json:"severity"`
+	Category       analyzers.Category `json:"category,omitempty"`
+	Posn           string             `json:"posn"`
+	Message        string             `json:"message"`
+	SuggestedFixes []jsonSuggestedFix `json:"suggested_fixes,omitempty"`
+	Related        []jsonRelatedInfo  `json:"related,omitempty"`
+}
+
+type jsonSuggestedFix struct {
+	Message string         `json:"message"`
+	Edits   []jsonTextEdit `json:"edits"`
+}
+
+type jsonTextEdit struct {
+	Filename string `json:"filename"`
+	Start    int    `json:"start"`
+	End      int    `json:"end"`
+	New      string `json:"new"`
+}
+
+type jsonRelatedInfo struct {
+	Posn    string `json:"posn"`
+	Message string `json:"message"`
+}
+
+// printJSON writes a mapping from package ID to analyzer name to either a
+// list of diagnostics or an error.
+func printJSON(w io.Writer, graph *checker.Graph) error {
+	tree := map[string]map[string]any{}
+	add := func(act *checker.Action, v any) {
+		m, ok := tree[act.Package.ID]
+		if !ok {
+			m = map[string]any{}
+			tree[act.Package.ID] = m
+		}
+		m[act.Analyzer.Name] = v
+	}
+	for act := range graph.All() {
+		if act.Err != nil {
+			add(act, struct {
+				Err string `json:"error"`
+			}{act.Err.Error()})
+		}
+	}
+	for _, act := range graph.Roots {
+		if act.Err != nil || len(act.Diagnostics) == 0 {
+			continue
+		}
+		fset := act.Package.Fset
+		e, _ := analyzers.Lookup(act.Analyzer.Name)
+		diags := make([]jsonDiagnostic, 0, len(act.Diagnostics))
+		for _, d := range act.Diagnostics {
+			jd := jsonDiagnostic{
+				Severity: analyzers.SeverityOf(act.Analyzer.Name),
+				Category: e.Category,
+				Posn:     fset.Position(d.Pos).String(),
+				Message:  d.Message,
+			}
+			for _, fix := range d.SuggestedFixes {
+				jf := jsonSuggestedFix{Message: fix.Message}
+				for _, edit := range fix.TextEdits {
+					jf.Edits = append(jf.Edits, jsonTextEdit{
+						Filename: fset.Position(edit.Pos).Filename,
+						Start:    fset.Position(edit.Pos).Offset,
+						End:      fset.Position(edit.End).Offset,
+						New:      string(edit.NewText),
+					})
+				}
+				jd.SuggestedFixes = append(jd.SuggestedFixes, jf)
+			}
+			for _, rel := range d.Related {
+				jd.Related = append(jd.Related, jsonRelatedInfo{Posn: fset.Position(rel.Pos).String(), Message: rel.Message})
+			}
+			diags = append(diags, jd)
+		}
+		add(act, diags)
+	}
+	data, err := json.MarshalIndent(tree, "", "\t")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
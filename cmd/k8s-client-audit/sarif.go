@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+	"runtime/debug"
+	"strings"
+
+	analyzers "github.com/amisstea/k8s-client-audit/internal/analyzers"
+	"github.com/amisstea/k8s-client-audit/internal/sarif"
+	"github.com/amisstea/k8s-client-audit/internal/suppress"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/checker"
+)
+
+const (
+	informationURI = "https://github.com/amisstea/k8s-client-audit"
+	// fingerprintKey names the partial fingerprint computed from the
+	// baseline key of a diagnostic.
+	fingerprintKey = "k8sClientAudit/v1"
+)
+
+// writeSARIF writes the diagnostics of the root actions of graph as a SARIF
+// log. Every selected analyzer is described as a rule, whether or not it
+// reported anything, so that viewers can show which checks ran.
+func writeSARIF(w io.Writer, graph *checker.Graph, selected []*analysis.Analyzer) error {
+	root, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	run := sarif.NewRun("k8s-client-audit", toolVersion(), informationURI, root)
+	for _, a := range selected {
+		run.AddRule(sarifRule(a))
+	}
+
+	keyer := baselineKeyer{}
+	forEachDiagnostic(graph, func(act *checker.Action, d analysis.Diagnostic) {
+		fset := act.Package.Fset
+		res := sarif.Result{
+			RuleID:    act.Analyzer.Name,
+			Level:     sarifLevel(analyzers.SeverityOf(act.Analyzer.Name)),
+			Message:   sarif.Message{Text: d.Message},
+			Locations: []sarif.Location{sarifLocation(root, fset, d.Pos, d.End, "")},
+			PartialFingerprints: map[string]string{
+				fingerprintKey: keyer.key(act, d).ID(),
+			},
+		}
+		for _, rel := range d.Related {
+			res.RelatedLocations = append(res.RelatedLocations, sarifLocation(root, fset, rel.Pos, rel.End, rel.Message))
+		}
+		run.AddResult(res)
+	})
+	return sarif.Write(w, run)
+}
+
+func sarifRule(a *analysis.Analyzer) sarif.Rule {
+	rule := sarif.Rule{
+		ID:               a.Name,
+		Name:             a.Name,
+		ShortDescription: &sarif.Message{Text: firstLine(a.Doc)},
+		FullDescription:  &sarif.Message{Text: a.Doc},
+		Help: &sarif.Message{Text: fmt.Sprintf("%s\n\nTo accept a reviewed finding, add %s %s -- <reason>.",
+			a.Doc, suppress.Prefix, a.Name)},
+		HelpURI:              informationURI + "#included-analyzers",
+		DefaultConfiguration: &sarif.RuleConfiguration{Level: sarifLevel(analyzers.SeverityOf(a.Name))},
+	}
+	if e, ok := analyzers.Lookup(a.Name); ok {
+		rule.Properties = map[string]any{
+			"category": string(e.Category),
+			"severity": string(e.Severity),
+			"maturity": string(e.Maturity),
+			"tags":     []string{string(e.Category), "kubernetes"},
+		}
+	}
+	return rule
+}
+
+func sarifLevel(s analyzers.Severity) string {
+	switch s {
+	case analyzers.SeverityError:
+		return sarif.LevelError
+	case analyzers.SeverityInfo:
+		return sarif.LevelNote
+	}
+	return sarif.LevelWarning
+}
+
+func sarifLocation(root string, fset *token.FileSet, pos, end token.Pos, message string) sarif.Location {
+	start := fset.Position(pos)
+	region := &sarif.Region{StartLine: start.Line, StartColumn: start.Column}
+	if end.IsValid() && end > pos {
+		e := fset.Position(end)
+		region.EndLine, region.EndColumn = e.Line, e.Column
+	}
+	loc := sarif.Location{PhysicalLocation: sarif.PhysicalLocation{
+		ArtifactLocation: sarif.Artifact(root, start.Filename),
+		Region:           region,
+	}}
+	if message != "" {
+		loc.Message = &sarif.Message{Text: message}
+	}
+	return loc
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+// toolVersion returns the module version the binary was built from, if known.
+func toolVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return ""
+}
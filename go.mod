@@ -4,6 +4,7 @@ go 1.24.5
 
 require (
 	golang.org/x/tools v0.36.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,121 @@
+package analyzers
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	insppass "golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// AnalyzerBlockingHandler flags informer event handlers that call the
+// Kubernetes API or sleep inline, blocking the shared informer's delivery of
+// events to every handler.
+//
+// Handlers are the AddFunc/UpdateFunc/DeleteFunc fields of
+// cache.ResourceEventHandlerFuncs literals, whether function literals or
+// functions and methods of the package, and the OnAdd/OnUpdate/OnDelete
+// methods of types implementing cache.ResourceEventHandler. Work started in
+// a goroutine does not block and is not reported.
+var AnalyzerBlockingHandler = &analysis.Analyzer{
+	Name:     "blockinghandler",
+	Doc:      "flags informer event handlers that call the API server or sleep inline instead of enqueueing work",
+	Run:      runBlockingHandler,
+	Requires: []*analysis.Analyzer{insppass.Analyzer},
+}
+
+func runBlockingHandler(pass *analysis.Pass) (any, error) {
+	insp := pass.ResultOf[insppass.Analyzer].(*inspector.Inspector)
+
+	decls := map[*types.Func]*ast.FuncDecl{}
+	insp.Preorder([]ast.Node{(*ast.FuncDecl)(nil)}, func(n ast.Node) {
+		fd := n.(*ast.FuncDecl)
+		if fn, ok := pass.TypesInfo.Defs[fd.Name].(*types.Func); ok && fd.Body != nil {
+			decls[fn] = fd
+		}
+	})
+
+	checked := map[*ast.BlockStmt]bool{}
+	check := func(handler string, body *ast.BlockStmt) {
+		if body == nil || checked[body] {
+			return
+		}
+		checked[body] = true
+		ast.Inspect(body, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.GoStmt:
+				return false
+			case *ast.CallExpr:
+				fn := calledFunc(pass.TypesInfo, n)
+				switch {
+				case fn == nil:
+				case isKubernetesMethodCall(fn, "Get", "List", "Create", "Update", "UpdateStatus", "Patch", "Delete", "Watch"):
+					pass.Reportf(n.Lparen, "informer event handler %s calls %s on the API server inline, blocking event delivery; enqueue a key to a workqueue and do the work in a worker", handler, fn.Name())
+				case fn.Pkg() != nil && fn.Pkg().Path() == "time" && fn.Name() == "Sleep":
+					pass.Reportf(n.Lparen, "informer event handler %s sleeps, blocking event delivery; enqueue a key to a workqueue, with AddAfter for delays", handler)
+				}
+			}
+			return true
+		})
+	}
+
+	nodes := []ast.Node{(*ast.CompositeLit)(nil), (*ast.FuncDecl)(nil)}
+	insp.Preorder(nodes, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.CompositeLit:
+			if !isNamed(pass.TypesInfo.TypeOf(n), PkgClientGoCache, "ResourceEventHandlerFuncs") {
+				return
+			}
+			for _, elt := range n.Elts {
+				kv, ok := elt.(*ast.KeyValueExpr)
+				if !ok {
+					continue
+				}
+				key, ok := kv.Key.(*ast.Ident)
+				if !ok {
+					continue
+				}
+				switch v := kv.Value.(type) {
+				case *ast.FuncLit:
+					check(key.Name, v.Body)
+				case *ast.Ident, *ast.SelectorExpr:
+					if fn, ok := pass.TypesInfo.Uses[calleeIdent(v)].(*types.Func); ok {
+						if fd := decls[fn.Origin()]; fd != nil {
+							check(key.Name, fd.Body)
+						}
+					}
+				}
+			}
+		case *ast.FuncDecl:
+			if n.Recv == nil || n.Body == nil {
+				return
+			}
+			switch n.Name.Name {
+			case "OnAdd", "OnUpdate", "OnDelete":
+			default:
+				return
+			}
+			fn, ok := pass.TypesInfo.Defs[n.Name].(*types.Func)
+			if ok && implementsEventHandler(fn.Type().(*types.Signature).Recv().Type()) {
+				check(n.Name.Name, n.Body)
+			}
+		}
+	})
+	return nil, nil
+}
+
+// implementsEventHandler reports whether t, or a pointer to it, has the
+// methods of cache.ResourceEventHandler.
+func implementsEventHandler(t types.Type) bool {
+	if _, ok := t.(*types.Pointer); !ok {
+		t = types.NewPointer(t)
+	}
+	mset := types.NewMethodSet(t)
+	for _, name := range []string{"OnAdd", "OnUpdate", "OnDelete"} {
+		if mset.Lookup(nil, name) == nil {
+			return false
+		}
+	}
+	return true
+}
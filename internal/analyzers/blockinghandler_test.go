@@ -0,0 +1,111 @@
+package analyzers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/amisstea/k8s-client-audit/internal/analyzers/testutil"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+const blockingHandlerImports = `package p
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+var (
+	_ = context.TODO
+	_ = time.Second
+	_ corev1.Pod
+	_ metav1.GetOptions
+	_ kubernetes.Interface
+	_ cache.ResourceEventHandlerFuncs
+	_ workqueue.Interface
+)
+`
+
+func runBlockingHandlerOnSrc(t *testing.T, src string) []analysis.Diagnostic {
+	t.Helper()
+	res, err := testutil.RunAnalyzerOnStubbedSrc(AnalyzerBlockingHandler, src)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	return res.Diagnostics
+}
+
+func TestBlockingHandler_FuncLiterals_Flagged(t *testing.T) {
+	src := blockingHandlerImports + `
+func register(informer cache.SharedIndexInformer, cs kubernetes.Interface) {
+	_, _ = informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj any) {
+			pod := obj.(*corev1.Pod)
+			_, _ = cs.CoreV1().Pods(pod.Namespace).Update(context.TODO(), pod, metav1.UpdateOptions{})
+		},
+		UpdateFunc: func(oldObj, newObj any) {
+			time.Sleep(time.Second)
+		},
+		DeleteFunc: func(obj any) {
+			go func() { _, _ = cs.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{}) }()
+		},
+	})
+}`
+	diags := runBlockingHandlerOnSrc(t, src)
+	if len(diags) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %v", diags)
+	}
+	if !strings.Contains(diags[0].Message, "AddFunc calls Update") || !strings.Contains(diags[1].Message, "UpdateFunc sleeps") {
+		t.Fatalf("unexpected messages: %v", diags)
+	}
+}
+
+func TestBlockingHandler_MethodValuesAndHandlerTypes_Flagged(t *testing.T) {
+	src := blockingHandlerImports + `
+type Controller struct {
+	cs    kubernetes.Interface
+	queue workqueue.Interface
+}
+
+func (c *Controller) register(informer cache.SharedIndexInformer) {
+	_, _ = informer.AddEventHandler(&cache.ResourceEventHandlerFuncs{AddFunc: c.onAdd})
+	_, _ = informer.AddEventHandler(c)
+}
+
+func (c *Controller) onAdd(obj any) {
+	_, _ = c.cs.CoreV1().Pods("ns").Get(context.TODO(), "a", metav1.GetOptions{})
+}
+
+func (c *Controller) OnAdd(obj any, isInInitialList bool) { c.queue.Add(obj) }
+func (c *Controller) OnUpdate(oldObj, newObj any)          { c.queue.Add(newObj) }
+func (c *Controller) OnDelete(obj any) {
+	_ = c.cs.CoreV1().Pods("ns").Delete(context.TODO(), "a", metav1.DeleteOptions{})
+}`
+	diags := runBlockingHandlerOnSrc(t, src)
+	if len(diags) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %v", diags)
+	}
+	if !strings.Contains(diags[0].Message, "AddFunc calls Get") || !strings.Contains(diags[1].Message, "OnDelete calls Delete") {
+		t.Fatalf("unexpected messages: %v", diags)
+	}
+}
+
+func TestBlockingHandler_Enqueue_NoDiag(t *testing.T) {
+	src := blockingHandlerImports + `
+func register(informer cache.SharedIndexInformer, queue workqueue.Interface) {
+	_, _ = informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj any) { queue.Add(obj) },
+		DeleteFunc: func(obj any) { queue.Add(obj) },
+	})
+}`
+	if diags := runBlockingHandlerOnSrc(t, src); len(diags) != 0 {
+		t.Fatalf("expected 0 diagnostics, got %v", diags)
+	}
+}
@@ -0,0 +1,156 @@
+package analyzers
+
+import (
+	"fmt"
+	"go/types"
+	"sort"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+	"golang.org/x/tools/go/ssa"
+)
+
+// AnalyzerBroadWatch flags controller-runtime controllers watching Pods,
+// Secrets, ConfigMaps or Events with nothing narrowing what is cached.
+//
+// For, Owns and Watches registrations of these types in builder chains ending
+// in Complete are recorded in a package fact, unless the registration or the
+// chain has a predicate other than ResourceVersionChangedPredicate, which only
+// drops resyncs. Cache restrictions are recorded too: types listed in a
+// cache.Options ByObject map and a DefaultLabelSelector for all types. Manager
+// options usually live in main, away from the controllers, so the analysis of
+// a main package combines the facts of its transitive imports and reports the
+// watches of types the binary does not restrict. Test binaries are skipped.
+var AnalyzerBroadWatch = &analysis.Analyzer{
+	Name:      "broadwatch",
+	Doc:       "flags controller-runtime For/Owns/Watches of Pods, Secrets, ConfigMaps and Events without predicates or a cache ByObject restriction",
+	Run:       runBroadWatch,
+	Requires:  []*analysis.Analyzer{buildssa.Analyzer},
+	FactTypes: []analysis.Fact{(*broadWatchesFact)(nil)},
+}
+
+// broadWatchesFact lists the unfiltered watches of high-cardinality types in
+// a package, and the types whose cache the package restricts.
+type broadWatchesFact struct {
+	Watches       []broadWatchSite
+	Restricted    []string
+	AllRestricted bool
+}
+
+// broadWatchSite is a builder registration, like Owns of
+// "k8s.io/api/core/v1.Pod".
+type broadWatchSite struct {
+	Pos, Method, Type string
+}
+
+func (*broadWatchesFact) AFact() {}
+
+func (f *broadWatchesFact) String() string {
+	return fmt.Sprintf("%d broad watches, %d restricted types", len(f.Watches), len(f.Restricted))
+}
+
+func runBroadWatch(pass *analysis.Pass) (any, error) {
+	ssaInfo := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+
+	// Package-level variables, like manager options, are initialized in init.
+	funcs := append([]*ssa.Function(nil), ssaInfo.SrcFuncs...)
+	if init := ssaInfo.Pkg.Func("init"); init != nil {
+		funcs = append(funcs, init)
+	}
+	fact := &broadWatchesFact{}
+	for _, fn := range funcs {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				switch instr := instr.(type) {
+				case *ssa.MapUpdate:
+					if isCacheByObjectMap(instr.Map.Type()) {
+						fact.Restricted = append(fact.Restricted, types.TypeString(deref(objectRoot(instr.Key).Type()), nil))
+					}
+				case *ssa.Store:
+					fa, ok := instr.Addr.(*ssa.FieldAddr)
+					if !ok || !isNamed(deref(fa.X.Type()), PkgControllerRuntimeCache, "Options") {
+						continue
+					}
+					if f := structField(deref(fa.X.Type()), fa.Field); f != nil && f.Name() == "DefaultLabelSelector" {
+						fact.AllRestricted = true
+					}
+				case ssa.CallInstruction:
+					if !isBuilderMethod(ssaCallee(instr.Common()), "Complete") {
+						continue
+					}
+					watches, filtered := builderWatches(ssaReceiver(instr.Common()))
+					if filtered {
+						continue
+					}
+					for _, w := range watches {
+						if !w.filtered && isHighCardinalityType(w.object) {
+							fact.Watches = append(fact.Watches, broadWatchSite{
+								Pos:    sitePosition(pass, w.call.Pos()),
+								Method: w.method,
+								Type:   types.TypeString(w.object, nil),
+							})
+						}
+					}
+				}
+			}
+		}
+	}
+	if len(fact.Watches) > 0 || len(fact.Restricted) > 0 || fact.AllRestricted {
+		pass.ExportPackageFact(fact)
+	}
+
+	if !isProgramPackage(pass) {
+		return nil, nil
+	}
+	watches := fact.Watches
+	restricted := map[string]bool{}
+	all := fact.AllRestricted
+	for _, t := range fact.Restricted {
+		restricted[t] = true
+	}
+	for _, pf := range pass.AllPackageFacts() {
+		f, ok := pf.Fact.(*broadWatchesFact)
+		if !ok || pf.Package == pass.Pkg {
+			continue
+		}
+		watches = append(watches, f.Watches...)
+		all = all || f.AllRestricted
+		for _, t := range f.Restricted {
+			restricted[t] = true
+		}
+	}
+	if all {
+		return nil, nil
+	}
+	pos := pass.Files[0].Name.Pos()
+	if main := pass.Pkg.Scope().Lookup("main"); main != nil {
+		pos = main.Pos()
+	}
+	sort.Slice(watches, func(i, j int) bool { return watches[i].Pos < watches[j].Pos })
+	for _, w := range watches {
+		if restricted[w.Type] {
+			continue
+		}
+		pass.Reportf(pos, "%s(%s) at %s caches every object of the type cluster-wide; add predicates, or restrict the cache with a label selector in cache.Options.ByObject",
+			w.Method, w.Type, w.Pos)
+	}
+	return nil, nil
+}
+
+// isCacheByObjectMap reports whether t is the ByObject map of the
+// controller-runtime cache options.
+func isCacheByObjectMap(t types.Type) bool {
+	m, ok := t.Underlying().(*types.Map)
+	return ok && isNamed(m.Elem(), PkgControllerRuntimeCache, "ByObject")
+}
+
+// isHighCardinalityType reports whether t is an object type that clusters
+// commonly hold many of, so caching all of them is expensive.
+func isHighCardinalityType(t types.Type) bool {
+	for _, name := range []string{"Pod", "Secret", "ConfigMap", "Event"} {
+		if isNamed(t, "k8s.io/api/core/v1", name) {
+			return true
+		}
+	}
+	return isNamed(t, "k8s.io/api/events/v1", "Event")
+}
@@ -0,0 +1,135 @@
+package analyzers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/amisstea/k8s-client-audit/internal/analyzers/testutil"
+)
+
+const broadWatchControllerSrc = `package controllers
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+type Widget struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+}
+
+func (w *Widget) DeepCopyObject() runtime.Object { c := *w; return &c }
+
+type Reconciler struct{}
+
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	return ctrl.Result{}, nil
+}
+
+func (r *Reconciler) SetupWithManager(mgr manager.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&Widget{}).
+		Owns(&corev1.Pod{}).
+		Owns(&corev1.ConfigMap{}, builder.WithPredicates(predicate.LabelChangedPredicate{})).
+		Watches(&corev1.Secret{}, &handler.EnqueueRequestForObject{}).
+		Complete(r)
+}
+`
+
+const broadWatchMainSrc = `package main
+
+import (
+	"example.com/op/controllers"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+var (
+	_ corev1.Pod
+	_ = labels.Everything
+	_ cache.Options
+	_ client.Object
+)
+
+func main() {
+	mgr, err := ctrl.NewManager(&rest.Config{}, manager.Options{%s})
+	if err != nil {
+		panic(err)
+	}
+	if err := (&controllers.Reconciler{}).SetupWithManager(mgr); err != nil {
+		panic(err)
+	}
+}
+`
+
+func runBroadWatchOnMain(t *testing.T, options string) []string {
+	t.Helper()
+	res, err := testutil.RunAnalyzerOnPackages(AnalyzerBroadWatch, map[string]string{
+		"example.com/op/controllers": broadWatchControllerSrc,
+		"example.com/op/cmd":         strings.Replace(broadWatchMainSrc, "%s", options, 1),
+	})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	var msgs []string
+	for _, d := range res.Diagnostics {
+		msgs = append(msgs, d.Message)
+	}
+	return msgs
+}
+
+func TestBroadWatch_UnrestrictedCoreTypes_Flagged(t *testing.T) {
+	msgs := runBroadWatchOnMain(t, "")
+	if len(msgs) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %v", msgs)
+	}
+	for i, want := range []string{"Owns(k8s.io/api/core/v1.Pod) at example.com/op/controllers/controllers.go:", "Watches(k8s.io/api/core/v1.Secret)"} {
+		if !strings.Contains(msgs[i], want) {
+			t.Fatalf("expected %q in %q", want, msgs[i])
+		}
+	}
+}
+
+func TestBroadWatch_ByObjectRestriction_Partial(t *testing.T) {
+	msgs := runBroadWatchOnMain(t, `Cache: cache.Options{ByObject: map[client.Object]cache.ByObject{
+		&corev1.Pod{}: {Label: labels.Everything()},
+	}}`)
+	if len(msgs) != 1 || !strings.Contains(msgs[0], "v1.Secret") {
+		t.Fatalf("expected 1 diagnostic for Secrets, got %v", msgs)
+	}
+}
+
+func TestBroadWatch_DefaultLabelSelector_NoDiag(t *testing.T) {
+	if msgs := runBroadWatchOnMain(t, `Cache: cache.Options{DefaultLabelSelector: labels.Everything()}`); len(msgs) != 0 {
+		t.Fatalf("expected 0 diagnostics, got %v", msgs)
+	}
+}
+
+func TestBroadWatch_TestBinaries_NoDiag(t *testing.T) {
+	mainSrc := strings.Replace(broadWatchMainSrc, "%s", "", 1)
+	res, err := testutil.RunAnalyzerOnFiles(AnalyzerBroadWatch, map[string]string{
+		"example.com/op/controllers/controllers.go": broadWatchControllerSrc,
+		"example.com/op/e2e/e2e_test.go":            mainSrc,
+		"example.com/op/cmd.test/testmain.go":       mainSrc,
+	})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(res.Diagnostics) != 0 {
+		t.Fatalf("expected 0 diagnostics, got %v", res.Diagnostics)
+	}
+}
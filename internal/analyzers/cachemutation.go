@@ -0,0 +1,162 @@
+package analyzers
+
+import (
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+	"golang.org/x/tools/go/ssa"
+)
+
+// AnalyzerCacheMutation flags writes to objects obtained from informer
+// caches, which are shared with every other reader of the cache.
+//
+// Objects returned by listers (Get and List) and by informer stores and
+// indexers (GetByKey, List, ByIndex) are followed within a function through
+// slice elements, type assertions and nested pointers, maps and slices.
+// Field writes, map updates, appends to their slices, and passing them to
+// client Update/Patch calls are reported; DeepCopy results are fresh objects
+// and may be modified freely. The controller-runtime cached client already
+// copies objects into the ones passed to Get and List, unless the cache is
+// configured with UnsafeDisableDeepCopy, so its reads are not tracked.
+var AnalyzerCacheMutation = &analysis.Analyzer{
+	Name:     "cachemutation",
+	Doc:      "flags mutation of objects obtained from informer caches and listers without DeepCopy",
+	Run:      runCacheMutation,
+	Requires: []*analysis.Analyzer{buildssa.Analyzer},
+}
+
+func runCacheMutation(pass *analysis.Pass) (any, error) {
+	ssaInfo := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+
+	for _, fn := range ssaInfo.SrcFuncs {
+		shared := cachedValues(fn)
+		if len(shared) == 0 {
+			continue
+		}
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				switch instr := instr.(type) {
+				case *ssa.Store:
+					if shared[instr.Addr] {
+						pass.Reportf(mutationPos(instr, instr.Addr), "write to an object from the informer cache; DeepCopy it before modifying")
+					}
+				case *ssa.MapUpdate:
+					if shared[instr.Map] {
+						pass.Reportf(mutationPos(instr, instr.Map), "map of an object from the informer cache is modified; DeepCopy the object before modifying")
+					}
+				case ssa.CallInstruction:
+					common := instr.Common()
+					if b, ok := common.Value.(*ssa.Builtin); ok && b.Name() == "append" && len(common.Args) > 0 && shared[common.Args[0]] {
+						pass.Reportf(instr.Pos(), "append to a slice of an object from the informer cache may modify the cache; DeepCopy the object before modifying")
+						continue
+					}
+					callee := ssaCallee(common)
+					if callee == nil || !isKubernetesMethodCall(callee, "Update", "UpdateStatus", "Patch") {
+						continue
+					}
+					for _, arg := range common.Args {
+						if shared[arg] {
+							pass.Reportf(instr.Pos(), "object from the informer cache passed to %s; DeepCopy it and modify the copy instead", callee.Name())
+							break
+						}
+					}
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// cachedValues returns the values of fn that point into objects owned by an
+// informer cache, including addresses of their fields and elements.
+func cachedValues(fn *ssa.Function) map[ssa.Value]bool {
+	shared := map[ssa.Value]bool{}
+	for changed := true; changed; {
+		changed = false
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				v, ok := instr.(ssa.Value)
+				if !ok || shared[v] || !isCachedValue(v, shared) {
+					continue
+				}
+				shared[v] = true
+				changed = true
+			}
+		}
+	}
+	return shared
+}
+
+// isCachedValue reports whether v is read from an informer cache, or derived
+// from a value in shared without copying the object.
+func isCachedValue(v ssa.Value, shared map[ssa.Value]bool) bool {
+	switch v := v.(type) {
+	case *ssa.Call:
+		return isCacheReadCall(ssaCallee(&v.Call))
+	case *ssa.Extract:
+		// pod, err := lister.Get(name); obj, exists, err := store.GetByKey(key)
+		call, ok := v.Tuple.(*ssa.Call)
+		return ok && v.Index == 0 && isCacheReadCall(ssaCallee(&call.Call))
+	case *ssa.FieldAddr:
+		return shared[v.X]
+	case *ssa.IndexAddr:
+		return shared[v.X]
+	case *ssa.UnOp:
+		return v.Op == token.MUL && shared[v.X] && isReferenceType(v.Type())
+	case *ssa.TypeAssert:
+		return shared[v.X]
+	case *ssa.ChangeType:
+		return shared[v.X]
+	case *ssa.MakeInterface:
+		return shared[v.X]
+	case *ssa.Phi:
+		for _, e := range v.Edges {
+			if shared[e] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isCacheReadCall reports whether fn returns objects owned by an informer
+// cache: the Get and List methods of listers, and the reads of cache stores
+// and indexers.
+func isCacheReadCall(fn *types.Func) bool {
+	if fn == nil || fn.Pkg() == nil {
+		return false
+	}
+	pkg := fn.Pkg().Path()
+	switch {
+	case strings.HasPrefix(pkg, "k8s.io/client-go/listers/") || strings.Contains(pkg, "/listers/"):
+		return fn.Name() == "Get" || fn.Name() == "List"
+	case pkg == PkgClientGoCache:
+		switch fn.Name() {
+		case "Get", "GetByKey", "List", "ByIndex":
+			return true
+		}
+	}
+	return false
+}
+
+// isReferenceType reports whether values of t share state with the value
+// they were loaded from.
+func isReferenceType(t types.Type) bool {
+	switch t.Underlying().(type) {
+	case *types.Pointer, *types.Map, *types.Slice, *types.Interface:
+		return true
+	}
+	return false
+}
+
+// mutationPos returns the position of a mutating instruction, falling back
+// to the value it modifies.
+func mutationPos(instr ssa.Instruction, target ssa.Value) token.Pos {
+	if pos := instr.Pos(); pos.IsValid() {
+		return pos
+	}
+	return target.Pos()
+}
@@ -0,0 +1,132 @@
+package analyzers
+
+import (
+	"testing"
+
+	"github.com/amisstea/k8s-client-audit/internal/analyzers/testutil"
+)
+
+const cacheMutationImports = `package p
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	_ = context.TODO
+	_ corev1.Pod
+	_ metav1.UpdateOptions
+	_ = labels.Everything
+	_ kubernetes.Interface
+	_ listers.PodLister
+	_ cache.Store
+)
+`
+
+func runCacheMutationOnSrc(t *testing.T, src string) int {
+	t.Helper()
+	res, err := testutil.RunAnalyzerOnStubbedSrc(AnalyzerCacheMutation, src)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	return len(res.Diagnostics)
+}
+
+func TestCacheMutation_ListerObjects_Flagged(t *testing.T) {
+	src := cacheMutationImports + `
+func sync(ctx context.Context, cs kubernetes.Interface, pods listers.PodLister) error {
+	pod, err := pods.Pods("ns").Get("a")
+	if err != nil {
+		return err
+	}
+	pod.Labels["synced"] = "true"
+	pod.Status.Phase = "Running"
+	_, err = cs.CoreV1().Pods("ns").Update(ctx, pod, metav1.UpdateOptions{})
+	return err
+}
+
+func all(pods listers.PodLister) {
+	list, _ := pods.List(labels.Everything())
+	for _, p := range list {
+		p.Spec.NodeName = ""
+	}
+}`
+	if n := runCacheMutationOnSrc(t, src); n != 4 {
+		t.Fatalf("expected 4 diagnostics, got %d", n)
+	}
+}
+
+func TestCacheMutation_StoreObjects_Flagged(t *testing.T) {
+	src := cacheMutationImports + `
+func sync(store cache.Store, key string) {
+	obj, exists, err := store.GetByKey(key)
+	if err != nil || !exists {
+		return
+	}
+	pod := obj.(*corev1.Pod)
+	pod.Annotations = map[string]string{}
+}`
+	if n := runCacheMutationOnSrc(t, src); n != 1 {
+		t.Fatalf("expected 1 diagnostic, got %d", n)
+	}
+}
+
+func TestCacheMutation_DeepCopy_NoDiag(t *testing.T) {
+	src := cacheMutationImports + `
+func sync(ctx context.Context, cs kubernetes.Interface, pods listers.PodLister) error {
+	cached, err := pods.Pods("ns").Get("a")
+	if err != nil {
+		return err
+	}
+	pod := cached.DeepCopy()
+	pod.Labels["synced"] = "true"
+	name := cached.Name
+	_ = name
+	_, err = cs.CoreV1().Pods("ns").Update(ctx, pod, metav1.UpdateOptions{})
+	return err
+}`
+	if n := runCacheMutationOnSrc(t, src); n != 0 {
+		t.Fatalf("expected 0 diagnostics, got %d", n)
+	}
+}
+
+// The controller-runtime cached client copies cached objects into the ones
+// passed to Get and List, so modifying them is safe unless the cache is
+// built with UnsafeDisableDeepCopy, which is not detected.
+func TestCacheMutation_CachedClientReads_NotTracked(t *testing.T) {
+	src := `package p
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func sync(ctx context.Context, c client.Client, key client.ObjectKey) error {
+	pod := &corev1.Pod{}
+	if err := c.Get(ctx, key, pod); err != nil {
+		return err
+	}
+	pod.Labels["synced"] = "true"
+	return c.Update(ctx, pod)
+}
+
+func all(ctx context.Context, c client.Client) {
+	var list corev1.PodList
+	_ = c.List(ctx, &list)
+	for i := range list.Items {
+		list.Items[i].Spec.NodeName = ""
+	}
+}`
+	if n := runCacheMutationOnSrc(t, src); n != 0 {
+		t.Fatalf("expected 0 diagnostics, got %d", n)
+	}
+}
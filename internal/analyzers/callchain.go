@@ -0,0 +1,124 @@
+package analyzers
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// maxCallChain bounds the length of recorded call chains, so that facts stay
+// small and diagnostics readable.
+const maxCallChain = 8
+
+// callChains finds the functions declared in the package that reach a call
+// to a function matched by target, directly or through other functions.
+// imported returns the chain recorded for a function of another package, or
+// nil. The chain of a function starts with the function itself and ends with
+// the matched callee.
+func callChains(pass *analysis.Pass, target func(*types.Func) bool, imported func(*types.Func) []string) map[*types.Func][]string {
+	var funcs []*types.Func
+	callees := map[*types.Func][]*types.Func{}
+	for _, f := range pass.Files {
+		for _, decl := range f.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || fd.Body == nil {
+				continue
+			}
+			fn, ok := pass.TypesInfo.Defs[fd.Name].(*types.Func)
+			if !ok {
+				continue
+			}
+			funcs = append(funcs, fn)
+			// Calls in function literals are attributed to the enclosing
+			// declaration.
+			ast.Inspect(fd.Body, func(n ast.Node) bool {
+				if call, ok := n.(*ast.CallExpr); ok {
+					if callee := calledFunc(pass.TypesInfo, call); callee != nil {
+						callees[fn] = append(callees[fn], callee)
+					}
+				}
+				return true
+			})
+		}
+	}
+
+	chains := map[*types.Func][]string{}
+	chainOf := func(callee *types.Func) []string {
+		switch {
+		case target(callee):
+			return []string{funcDisplayName(callee)}
+		case chains[callee] != nil:
+			return chains[callee]
+		case callee.Pkg() != nil && callee.Pkg() != pass.Pkg:
+			return imported(callee)
+		}
+		return nil
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, fn := range funcs {
+			if chains[fn] != nil {
+				continue
+			}
+			for _, callee := range callees[fn] {
+				if rest := chainOf(callee); rest != nil && len(rest) < maxCallChain {
+					chains[fn] = append([]string{funcDisplayName(fn)}, rest...)
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return chains
+}
+
+// chainOfCall returns the chain of the function called by call, looking in
+// chains for functions of this package and in imported for the others.
+func chainOfCall(pass *analysis.Pass, chains map[*types.Func][]string, imported func(*types.Func) []string, call *ast.CallExpr) []string {
+	fn := calledFunc(pass.TypesInfo, call)
+	if fn == nil {
+		return nil
+	}
+	if chain := chains[fn]; chain != nil {
+		return chain
+	}
+	if fn.Pkg() != nil && fn.Pkg() != pass.Pkg {
+		return imported(fn)
+	}
+	return nil
+}
+
+// calledFunc returns the function or method called by call, including
+// interface methods, or nil for calls of function values and builtins.
+func calledFunc(info *types.Info, call *ast.CallExpr) *types.Func {
+	fn, ok := typeutil.Callee(info, call).(*types.Func)
+	if !ok {
+		return nil
+	}
+	return fn.Origin()
+}
+
+// funcDisplayName names fn as "pkg.Func" or "pkg.Type.Method".
+func funcDisplayName(fn *types.Func) string {
+	name := fn.Name()
+	if sig, ok := fn.Type().(*types.Signature); ok && sig.Recv() != nil {
+		switch t := deref(sig.Recv().Type()).(type) {
+		case *types.Named:
+			name = t.Obj().Name() + "." + name
+		case *types.Interface:
+			name = "interface." + name
+		}
+	}
+	if fn.Pkg() != nil {
+		name = fn.Pkg().Name() + "." + name
+	}
+	return name
+}
+
+// formatCallChain renders a chain for diagnostics.
+func formatCallChain(chain []string) string {
+	return strings.Join(chain, " -> ")
+}
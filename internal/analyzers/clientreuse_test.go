@@ -47,3 +47,16 @@ func init(){ _ = NewForConfig(nil) }`
 		t.Fatalf("did not expect diagnostic for init-time creation, got %d", len(diags))
 	}
 }
+
+func TestClientReuse_ConfiguredHotPath_Flagged(t *testing.T) {
+	testutil.SetAnalyzerFlag(t, AnalyzerClientReuse, "hot-paths", "p.Worker.Process")
+	src := `package a
+func NewForConfig(x any) any { return nil }
+type Worker struct{}
+func (w *Worker) Process(){ _ = NewForConfig(nil) }
+func Process(){ _ = NewForConfig(nil) }`
+	diags := runClientReuseAnalyzerOnSrc(t, src)
+	if len(diags) != 1 {
+		t.Fatalf("expected 1 diagnostic for the configured hot path, got %d", len(diags))
+	}
+}
@@ -0,0 +1,167 @@
+package analyzers
+
+import (
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+	"golang.org/x/tools/go/ssa"
+)
+
+// AnalyzerConflictRetry flags Update and UpdateStatus calls whose conflict
+// errors are neither retried nor handled.
+//
+// Updates carry the resourceVersion the object was read at and fail with a
+// conflict when the object changed since. Calls in functions run by
+// retry.RetryOnConflict or retry.OnError, and in Reconcile and the functions
+// of the package it calls, where controller-runtime requeues on error, are
+// not reported; neither are calls whose error is checked with
+// apierrors.IsConflict.
+var AnalyzerConflictRetry = &analysis.Analyzer{
+	Name:     "conflictretry",
+	Doc:      "flags Update calls outside Reconcile that neither retry with retry.RetryOnConflict nor check apierrors.IsConflict",
+	Run:      runConflictRetry,
+	Requires: []*analysis.Analyzer{buildssa.Analyzer},
+}
+
+func runConflictRetry(pass *analysis.Pass) (any, error) {
+	ssaInfo := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+
+	var reconcilers, retried []*ssa.Function
+	for _, fn := range ssaInfo.SrcFuncs {
+		if isReconcileFunc(fn.Object()) {
+			reconcilers = append(reconcilers, fn)
+		}
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				ci, ok := instr.(ssa.CallInstruction)
+				if !ok || !isConflictRetry(ssaCallee(ci.Common())) {
+					continue
+				}
+				for _, arg := range ssaArgs(ci.Common()) {
+					if f := funcValue(arg); f != nil {
+						retried = append(retried, f)
+					}
+				}
+			}
+		}
+	}
+	inReconcile := reachableFuncs(reconcilers)
+	inRetry := reachableFuncs(retried)
+
+	for _, fn := range ssaInfo.SrcFuncs {
+		if inReconcile[fn] || inRetry[fn] {
+			continue
+		}
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				ci, ok := instr.(ssa.CallInstruction)
+				if !ok {
+					continue
+				}
+				callee := ssaCallee(ci.Common())
+				if callee == nil || !isKubernetesMethodCall(callee, "Update", "UpdateStatus") {
+					continue
+				}
+				if call, ok := ci.(*ssa.Call); ok && checksConflict(callError(call)) {
+					continue
+				}
+				pass.Reportf(ci.Pos(), "%s fails on conflicting writes and is not retried; wrap the read-modify-write in retry.RetryOnConflict or handle apierrors.IsConflict", callee.Name())
+			}
+		}
+	}
+	return nil, nil
+}
+
+// isConflictRetry reports whether fn retries the function passed to it on
+// conflicts.
+func isConflictRetry(fn *types.Func) bool {
+	return fn != nil && fn.Pkg() != nil && fn.Pkg().Path() == PkgClientGoRetry &&
+		(fn.Name() == "RetryOnConflict" || fn.Name() == "OnError")
+}
+
+// funcValue returns the function v refers to, for function literals,
+// closures and method values, or nil.
+func funcValue(v ssa.Value) *ssa.Function {
+	switch v := v.(type) {
+	case *ssa.Function:
+		return v
+	case *ssa.MakeClosure:
+		f, _ := v.Fn.(*ssa.Function)
+		return f
+	}
+	return nil
+}
+
+// reachableFuncs returns roots and the functions with bodies they call
+// statically, or define, directly or transitively.
+func reachableFuncs(roots []*ssa.Function) map[*ssa.Function]bool {
+	seen := map[*ssa.Function]bool{}
+	stack := append([]*ssa.Function(nil), roots...)
+	for len(stack) > 0 {
+		fn := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[fn] {
+			continue
+		}
+		seen[fn] = true
+		stack = append(stack, fn.AnonFuncs...)
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				ci, ok := instr.(ssa.CallInstruction)
+				if !ok {
+					continue
+				}
+				if callee := ci.Common().StaticCallee(); callee != nil && callee.Blocks != nil {
+					stack = append(stack, callee)
+				}
+			}
+		}
+	}
+	return seen
+}
+
+// callError returns the error result of call, or nil if it is discarded.
+func callError(call *ssa.Call) ssa.Value {
+	results := call.Call.Signature().Results()
+	if results.Len() == 1 {
+		return call
+	}
+	for _, ref := range *call.Referrers() {
+		if ex, ok := ref.(*ssa.Extract); ok && ex.Index == results.Len()-1 {
+			return ex
+		}
+	}
+	return nil
+}
+
+// checksConflict reports whether err, or a variable it flows into, is passed
+// to apierrors.IsConflict.
+func checksConflict(err ssa.Value) bool {
+	if err == nil {
+		return false
+	}
+	seen := map[ssa.Value]bool{}
+	stack := []ssa.Value{err}
+	for len(stack) > 0 {
+		v := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[v] || v.Referrers() == nil {
+			continue
+		}
+		seen[v] = true
+		for _, ref := range *v.Referrers() {
+			switch ref := ref.(type) {
+			case *ssa.Phi:
+				stack = append(stack, ref)
+			case *ssa.ChangeInterface:
+				stack = append(stack, ref)
+			case ssa.CallInstruction:
+				if fn := ssaCallee(ref.Common()); fn != nil && fn.Pkg() != nil && fn.Pkg().Path() == PkgAPIErrors && fn.Name() == "IsConflict" {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
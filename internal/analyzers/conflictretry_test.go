@@ -0,0 +1,121 @@
+package analyzers
+
+import (
+	"testing"
+
+	"github.com/amisstea/k8s-client-audit/internal/analyzers/testutil"
+)
+
+const conflictRetryImports = `package p
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var (
+	_ = context.TODO
+	_ corev1.Pod
+	_ = apierrors.IsConflict
+	_ metav1.UpdateOptions
+	_ kubernetes.Interface
+	_ = retry.DefaultRetry
+	_ client.Client
+	_ reconcile.Result
+)
+`
+
+func runConflictRetryOnSrc(t *testing.T, src string) int {
+	t.Helper()
+	res, err := testutil.RunAnalyzerOnStubbedSrc(AnalyzerConflictRetry, src)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	return len(res.Diagnostics)
+}
+
+func TestConflictRetry_UnretriedUpdates_Flagged(t *testing.T) {
+	src := conflictRetryImports + `
+func label(ctx context.Context, c client.Client, pod *corev1.Pod) error {
+	pod.Labels["synced"] = "true"
+	return c.Update(ctx, pod)
+}
+
+func markRunning(ctx context.Context, cs kubernetes.Interface, pod *corev1.Pod) {
+	pod.Status.Phase = "Running"
+	if _, err := cs.CoreV1().Pods("ns").UpdateStatus(ctx, pod, metav1.UpdateOptions{}); err != nil {
+		panic(err)
+	}
+}`
+	if n := runConflictRetryOnSrc(t, src); n != 2 {
+		t.Fatalf("expected 2 diagnostics, got %d", n)
+	}
+}
+
+func TestConflictRetry_RetriedOrChecked_NoDiag(t *testing.T) {
+	src := conflictRetryImports + `
+type labeler struct {
+	c   client.Client
+	key client.ObjectKey
+}
+
+func (l *labeler) update(ctx context.Context) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var pod corev1.Pod
+		if err := l.c.Get(ctx, l.key, &pod); err != nil {
+			return err
+		}
+		pod.Labels["synced"] = "true"
+		return l.c.Update(ctx, &pod)
+	})
+}
+
+func (l *labeler) once() error {
+	var pod corev1.Pod
+	return l.c.Update(context.TODO(), &pod)
+}
+
+func (l *labeler) run() error {
+	return retry.RetryOnConflict(retry.DefaultRetry, l.once)
+}
+
+func markRunning(ctx context.Context, c client.Client, pod *corev1.Pod) error {
+	pod.Status.Phase = "Running"
+	err := c.Status().Update(ctx, pod)
+	if apierrors.IsConflict(err) {
+		return nil
+	}
+	return err
+}`
+	if n := runConflictRetryOnSrc(t, src); n != 0 {
+		t.Fatalf("expected 0 diagnostics, got %d", n)
+	}
+}
+
+func TestConflictRetry_ReconcilePath_NoDiag(t *testing.T) {
+	src := conflictRetryImports + `
+type Reconciler struct{ c client.Client }
+
+func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	var pod corev1.Pod
+	if err := r.c.Get(ctx, req.NamespacedName, &pod); err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, r.label(ctx, &pod)
+}
+
+func (r *Reconciler) label(ctx context.Context, pod *corev1.Pod) error {
+	pod.Labels["synced"] = "true"
+	return r.c.Update(ctx, pod)
+}`
+	if n := runConflictRetryOnSrc(t, src); n != 0 {
+		t.Fatalf("expected 0 diagnostics, got %d", n)
+	}
+}
@@ -0,0 +1,317 @@
+package analyzers
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+	"golang.org/x/tools/go/ssa"
+)
+
+// constTracer resolves the values of expressions that are constant without
+// being Go constants: variables, struct fields and flags assigned a single
+// constant, helper functions returning one, and arithmetic on these.
+type constTracer struct {
+	info *types.Info
+	// sites maps the position of a store to a struct field, the selector of
+	// cfg.QPS = v or the colon of QPS: v, to the stored value.
+	sites map[token.Pos]ssa.Value
+	// globals and fields hold the values stored to package variables and to
+	// struct fields anywhere in the package. A nil value is an unknown one,
+	// such as a variable whose address is passed to a function.
+	globals map[*ssa.Global][]ssa.Value
+	fields  map[*types.Var][]ssa.Value
+	active  map[ssa.Value]bool
+}
+
+func newConstTracer(pass *analysis.Pass) *constTracer {
+	ssaInfo := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+	t := &constTracer{
+		info:    pass.TypesInfo,
+		sites:   map[token.Pos]ssa.Value{},
+		globals: map[*ssa.Global][]ssa.Value{},
+		fields:  map[*types.Var][]ssa.Value{},
+		active:  map[ssa.Value]bool{},
+	}
+	// Package-level variables are initialized in init.
+	funcs := ssaInfo.SrcFuncs
+	if init := ssaInfo.Pkg.Func("init"); init != nil {
+		funcs = append(funcs[:len(funcs):len(funcs)], init)
+	}
+	for _, fn := range funcs {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				if st, ok := instr.(*ssa.Store); ok {
+					if _, ok := st.Addr.(*ssa.FieldAddr); ok {
+						t.sites[st.Pos()] = st.Val
+					}
+				}
+				for _, op := range instr.Operands(nil) {
+					switch addr := (*op).(type) {
+					case *ssa.Global:
+						if v, ok := storedAt(addr, instr); ok {
+							t.globals[addr] = append(t.globals[addr], v)
+						}
+					case *ssa.FieldAddr:
+						if v, ok := storedAt(addr, instr); ok {
+							f := structField(deref(addr.X.Type()), addr.Field)
+							t.fields[f] = append(t.fields[f], v)
+						}
+					}
+				}
+			}
+		}
+	}
+	return t
+}
+
+// exprValue returns the constant value of e, the value stored to a struct
+// field at pos, or nil if it is not constant.
+func (t *constTracer) exprValue(e ast.Expr, pos token.Pos) constant.Value {
+	if tv, ok := t.info.Types[e]; ok && tv.Value != nil {
+		return tv.Value
+	}
+	if v, ok := t.sites[pos]; ok {
+		return t.value(v, 0)
+	}
+	return nil
+}
+
+// value returns the constant value of v, or nil.
+func (t *constTracer) value(v ssa.Value, depth int) constant.Value {
+	if v == nil || depth > maxTraceDepth || t.active[v] {
+		return nil
+	}
+	t.active[v] = true
+	defer delete(t.active, v)
+	depth++
+
+	switch v := v.(type) {
+	case *ssa.Const:
+		if v.Value == nil {
+			return zeroConstant(v.Type())
+		}
+		return v.Value
+	case *ssa.ChangeType:
+		return t.value(v.X, depth)
+	case *ssa.Convert:
+		return convertConstant(t.value(v.X, depth), v.Type())
+	case *ssa.UnOp:
+		switch v.Op {
+		case token.MUL:
+			return t.same(t.stored(v.X, depth), depth)
+		case token.SUB, token.XOR:
+			if x := t.value(v.X, depth); x != nil && x.Kind() != constant.Bool && x.Kind() != constant.String {
+				return constant.UnaryOp(v.Op, x, 0)
+			}
+		}
+	case *ssa.BinOp:
+		return binaryConstant(v.Op, t.value(v.X, depth), t.value(v.Y, depth))
+	case *ssa.Phi:
+		return t.same(v.Edges, depth)
+	case *ssa.Field:
+		return t.same(t.fields[structField(v.X.Type(), v.Field)], depth)
+	case *ssa.Call:
+		return t.same(returnedValues(v.Call.StaticCallee(), 0), depth)
+	case *ssa.Extract:
+		if call, ok := v.Tuple.(*ssa.Call); ok {
+			return t.same(returnedValues(call.Call.StaticCallee(), v.Index), depth)
+		}
+	}
+	return nil
+}
+
+// same returns the constant value of every one of vals, or nil if they are
+// not all the same constant.
+func (t *constTracer) same(vals []ssa.Value, depth int) constant.Value {
+	var c constant.Value
+	for _, v := range vals {
+		vc := t.value(v, depth)
+		if vc == nil || c != nil && (vc.Kind() != c.Kind() || !constant.Compare(c, token.EQL, vc)) {
+			return nil
+		}
+		c = vc
+	}
+	return c
+}
+
+// stored returns the values stored at addr: a package variable, a struct
+// field, a local variable, or a flag.
+func (t *constTracer) stored(addr ssa.Value, depth int) []ssa.Value {
+	switch addr := addr.(type) {
+	case *ssa.Global:
+		return t.globals[addr]
+	case *ssa.FieldAddr:
+		return t.fields[structField(deref(addr.X.Type()), addr.Field)]
+	case *ssa.Alloc:
+		var vals []ssa.Value
+		for _, ref := range *addr.Referrers() {
+			if v, ok := storedAt(addr, ref); ok {
+				vals = append(vals, v)
+			}
+		}
+		return vals
+	case *ssa.Call:
+		// qps := flag.Float64("qps", 5, "...") holds the default unless the
+		// flag is set.
+		if fn := ssaCallee(&addr.Call); isFlagFunc(fn) {
+			if args := ssaArgs(&addr.Call); len(args) == 3 {
+				return args[1:2]
+			}
+		}
+	case *ssa.UnOp:
+		// A pointer loaded from a variable, like a flag in a package variable.
+		if addr.Op != token.MUL || depth > maxTraceDepth {
+			return nil
+		}
+		if ptrs := t.stored(addr.X, depth+1); len(ptrs) == 1 {
+			return t.stored(ptrs[0], depth+1)
+		}
+	}
+	return nil
+}
+
+// storedAt returns the value instr stores at addr: the value of a Store, the
+// default of a flag bound to addr, or nil if addr is otherwise passed to a
+// call. ok is false if instr does not write at addr.
+func storedAt(addr ssa.Value, instr ssa.Instruction) (v ssa.Value, ok bool) {
+	switch instr := instr.(type) {
+	case *ssa.Store:
+		if instr.Addr == addr {
+			return instr.Val, true
+		}
+		return nil, instr.Val == addr
+	case ssa.CallInstruction:
+		args := ssaArgs(instr.Common())
+		for i, arg := range args {
+			if arg != addr {
+				continue
+			}
+			// flag.Float64Var(&qps, "qps", 5, "...")
+			if i == 0 && len(args) == 4 && isFlagFunc(ssaCallee(instr.Common())) {
+				return args[2], true
+			}
+			return nil, true
+		}
+	}
+	return nil, false
+}
+
+// isFlagFunc reports whether fn defines a flag of the standard flag package,
+// as a function or a FlagSet method.
+func isFlagFunc(fn *types.Func) bool {
+	if fn == nil || fn.Pkg() == nil || fn.Pkg().Path() != "flag" {
+		return false
+	}
+	switch fn.Name() {
+	case "Bool", "Duration", "Float64", "Int", "Int64", "String", "Uint", "Uint64",
+		"BoolVar", "DurationVar", "Float64Var", "IntVar", "Int64Var", "StringVar", "UintVar", "Uint64Var":
+		return true
+	}
+	return false
+}
+
+// returnedValues returns the i-th result of every return of fn, or nil if
+// fn has no body in the package.
+func returnedValues(fn *ssa.Function, i int) []ssa.Value {
+	if fn == nil {
+		return nil
+	}
+	var vals []ssa.Value
+	for _, b := range fn.Blocks {
+		if ret, ok := b.Instrs[len(b.Instrs)-1].(*ssa.Return); ok && i < len(ret.Results) {
+			vals = append(vals, ret.Results[i])
+		}
+	}
+	return vals
+}
+
+// zeroConstant returns the zero value of the basic type t, or nil.
+func zeroConstant(t types.Type) constant.Value {
+	b, ok := t.Underlying().(*types.Basic)
+	switch {
+	case !ok:
+		return nil
+	case b.Info()&types.IsInteger != 0:
+		return constant.MakeInt64(0)
+	case b.Info()&types.IsFloat != 0:
+		return constant.MakeFloat64(0)
+	case b.Info()&types.IsString != 0:
+		return constant.MakeString("")
+	case b.Info()&types.IsBoolean != 0:
+		return constant.MakeBool(false)
+	}
+	return nil
+}
+
+// isZeroConstant reports whether c is the zero value of its type.
+func isZeroConstant(c constant.Value) bool {
+	switch c.Kind() {
+	case constant.Int, constant.Float:
+		return constant.Sign(c) == 0
+	case constant.String:
+		return constant.StringVal(c) == ""
+	case constant.Bool:
+		return !constant.BoolVal(c)
+	}
+	return false
+}
+
+// convertConstant converts x to the basic type t, truncating floats
+// converted to integers.
+func convertConstant(x constant.Value, t types.Type) constant.Value {
+	b, ok := t.Underlying().(*types.Basic)
+	if x == nil || !ok {
+		return nil
+	}
+	switch {
+	case b.Info()&types.IsInteger != 0 && x.Kind() == constant.Float:
+		f, _ := constant.Float64Val(x)
+		return constant.MakeInt64(int64(f))
+	case b.Info()&types.IsInteger != 0 && x.Kind() == constant.Int:
+		return x
+	case b.Info()&types.IsFloat != 0 && (x.Kind() == constant.Int || x.Kind() == constant.Float):
+		return constant.ToFloat(x)
+	}
+	return nil
+}
+
+// binaryConstant applies the arithmetic operator op to x and y.
+func binaryConstant(op token.Token, x, y constant.Value) constant.Value {
+	if x == nil || y == nil || !isNumeric(x) || !isNumeric(y) {
+		return nil
+	}
+	switch op {
+	case token.SHL, token.SHR:
+		if s, ok := constant.Uint64Val(y); ok && x.Kind() == constant.Int && s < 64 {
+			return constant.Shift(x, op, uint(s))
+		}
+	case token.QUO, token.REM:
+		if constant.Sign(y) == 0 {
+			return nil
+		}
+		if x.Kind() == constant.Int && y.Kind() == constant.Int {
+			if op == token.QUO {
+				op = token.QUO_ASSIGN // integer division
+			}
+			return constant.BinaryOp(x, op, y)
+		}
+		if op == token.QUO {
+			return constant.BinaryOp(x, op, y)
+		}
+	case token.ADD, token.SUB, token.MUL:
+		return constant.BinaryOp(x, op, y)
+	case token.AND, token.OR, token.XOR, token.AND_NOT:
+		if x.Kind() == constant.Int && y.Kind() == constant.Int {
+			return constant.BinaryOp(x, op, y)
+		}
+	}
+	return nil
+}
+
+func isNumeric(x constant.Value) bool {
+	return x.Kind() == constant.Int || x.Kind() == constant.Float
+}
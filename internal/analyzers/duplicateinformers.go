@@ -0,0 +1,124 @@
+package analyzers
+
+import (
+	"fmt"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+	"golang.org/x/tools/go/ssa"
+)
+
+// AnalyzerDuplicateInformers flags binaries whose packages create several
+// shared informer factories watching the same resource type.
+//
+// Every package records the factories it constructs, with the resources
+// requested from each, in a package fact. The analysis of a main package
+// combines the facts of its transitive imports and reports each resource
+// watched by more than one factory, since every factory opens its own watch.
+// Test binaries are skipped.
+var AnalyzerDuplicateInformers = &analysis.Analyzer{
+	Name:      "duplicateinformers",
+	Doc:       "flags several SharedInformerFactory instances in one binary watching the same resource type",
+	Run:       runDuplicateInformers,
+	Requires:  []*analysis.Analyzer{buildssa.Analyzer},
+	FactTypes: []analysis.Fact{(*informerFactoriesFact)(nil)},
+}
+
+// informerFactoriesFact lists the informer factories constructed in a
+// package.
+type informerFactoriesFact struct{ Factories []informerFactorySite }
+
+// informerFactorySite is a factory construction and the resources whose
+// informers are requested from it, like "Core().V1().Pods()".
+type informerFactorySite struct {
+	Pos       string
+	Resources []string
+}
+
+func (*informerFactoriesFact) AFact() {}
+
+func (f *informerFactoriesFact) String() string {
+	return fmt.Sprintf("constructs %d informer factories", len(f.Factories))
+}
+
+func runDuplicateInformers(pass *analysis.Pass) (any, error) {
+	ssaInfo := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+
+	resources := map[any]map[string]bool{}
+	var ctors []*ssa.Call
+	for _, fn := range ssaInfo.SrcFuncs {
+		for _, r := range factoryEvents(fn).requests {
+			if res := informerResource(r.instr.Common()); res != "" {
+				if resources[r.id] == nil {
+					resources[r.id] = map[string]bool{}
+				}
+				resources[r.id][res] = true
+			}
+		}
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				if call, ok := instr.(*ssa.Call); ok && isInformerFactoryConstructor(ssaCallee(&call.Call)) {
+					ctors = append(ctors, call)
+				}
+			}
+		}
+	}
+
+	var sites []informerFactorySite
+	for _, call := range ctors {
+		site := informerFactorySite{Pos: sitePosition(pass, call.Pos())}
+		if id := factoryID(call, nil); id != nil {
+			for res := range resources[id] {
+				site.Resources = append(site.Resources, res)
+			}
+			sort.Strings(site.Resources)
+		}
+		sites = append(sites, site)
+	}
+	if len(sites) > 0 {
+		pass.ExportPackageFact(&informerFactoriesFact{Factories: sites})
+	}
+
+	if !isProgramPackage(pass) {
+		return nil, nil
+	}
+	for _, pf := range pass.AllPackageFacts() {
+		if f, ok := pf.Fact.(*informerFactoriesFact); ok && pf.Package != pass.Pkg {
+			sites = append(sites, f.Factories...)
+		}
+	}
+	watchers := map[string][]string{}
+	for _, site := range sites {
+		for _, res := range site.Resources {
+			watchers[res] = append(watchers[res], site.Pos)
+		}
+	}
+	pos := pass.Files[0].Name.Pos()
+	if main := pass.Pkg.Scope().Lookup("main"); main != nil {
+		pos = main.Pos()
+	}
+	names := make([]string, 0, len(watchers))
+	for res := range watchers {
+		names = append(names, res)
+	}
+	sort.Strings(names)
+	for _, res := range names {
+		if at := watchers[res]; len(at) > 1 {
+			sort.Strings(at)
+			pass.Reportf(pos, "%d informer factories in this binary watch %s (%s); share one SharedInformerFactory per clientset to avoid duplicate watches",
+				len(at), res, strings.Join(at, ", "))
+		}
+	}
+	return nil, nil
+}
+
+// sitePosition renders pos as "pkg/path/file.go:line" for facts, which
+// outlive the file set of the pass.
+func sitePosition(pass *analysis.Pass, pos token.Pos) string {
+	p := pass.Fset.Position(pos)
+	return fmt.Sprintf("%s/%s:%d", pass.Pkg.Path(), filepath.Base(p.Filename), p.Line)
+}
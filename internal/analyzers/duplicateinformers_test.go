@@ -0,0 +1,133 @@
+package analyzers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/amisstea/k8s-client-audit/internal/analyzers/testutil"
+)
+
+const podWatcherSrc = `package %s
+
+import (
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	listers "k8s.io/client-go/listers/core/v1"
+)
+
+func Pods(cs kubernetes.Interface, stop chan struct{}) listers.PodLister {
+	factory := informers.NewSharedInformerFactory(cs, 0)
+	lister := factory.Core().V1().Pods().Lister()
+	factory.Start(stop)
+	return lister
+}
+`
+
+func TestDuplicateInformers_AcrossPackages_Flagged(t *testing.T) {
+	mainSrc := `package main
+
+import (
+	"example.com/a"
+	"example.com/b"
+	"k8s.io/client-go/kubernetes"
+)
+
+func main() {
+	var cs kubernetes.Interface
+	stop := make(chan struct{})
+	_ = a.Pods(cs, stop)
+	_ = b.Pods(cs, stop)
+}
+`
+	res, err := testutil.RunAnalyzerOnPackages(AnalyzerDuplicateInformers, map[string]string{
+		"example.com/a":   strings.Replace(podWatcherSrc, "%s", "a", 1),
+		"example.com/b":   strings.Replace(podWatcherSrc, "%s", "b", 1),
+		"example.com/cmd": mainSrc,
+	})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(res.Diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %v", res.Diagnostics)
+	}
+	msg := res.Diagnostics[0].Message
+	for _, want := range []string{"2 informer factories", "Core().V1().Pods()", "example.com/a/a.go:10", "example.com/b/b.go:10"} {
+		if !strings.Contains(msg, want) {
+			t.Fatalf("expected %q in %q", want, msg)
+		}
+	}
+}
+
+func TestDuplicateInformers_SingleFactory_NoDiag(t *testing.T) {
+	mainSrc := `package main
+
+import (
+	"example.com/a"
+	"k8s.io/client-go/kubernetes"
+)
+
+func main() {
+	var cs kubernetes.Interface
+	stop := make(chan struct{})
+	_ = a.Pods(cs, stop)
+	_ = a.Pods(cs, stop)
+}
+`
+	res, err := testutil.RunAnalyzerOnPackages(AnalyzerDuplicateInformers, map[string]string{
+		"example.com/a":   strings.Replace(podWatcherSrc, "%s", "a", 1),
+		"example.com/cmd": mainSrc,
+	})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(res.Diagnostics) != 0 {
+		t.Fatalf("expected 0 diagnostics, got %v", res.Diagnostics)
+	}
+}
+
+func TestDuplicateInformers_TestBinaries_NoDiag(t *testing.T) {
+	testSrc := `package main
+
+import (
+	"testing"
+
+	"example.com/a"
+	"example.com/b"
+	"k8s.io/client-go/kubernetes"
+)
+
+func TestPods(t *testing.T) {
+	var cs kubernetes.Interface
+	stop := make(chan struct{})
+	_ = a.Pods(cs, stop)
+	_ = b.Pods(cs, stop)
+}
+`
+	testMainSrc := `package main
+
+import (
+	"example.com/a"
+	"example.com/b"
+	"k8s.io/client-go/kubernetes"
+)
+
+func main() {
+	var cs kubernetes.Interface
+	stop := make(chan struct{})
+	_ = a.Pods(cs, stop)
+	_ = b.Pods(cs, stop)
+}
+`
+	res, err := testutil.RunAnalyzerOnFiles(AnalyzerDuplicateInformers, map[string]string{
+		"example.com/a/a.go":               strings.Replace(podWatcherSrc, "%s", "a", 1),
+		"example.com/b/b.go":               strings.Replace(podWatcherSrc, "%s", "b", 1),
+		"example.com/e2e/e2e_test.go":      testSrc,
+		"example.com/cmd.test/testmain.go": testMainSrc,
+	})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(res.Diagnostics) != 0 {
+		t.Fatalf("expected 0 diagnostics, got %v", res.Diagnostics)
+	}
+}
@@ -9,12 +9,26 @@ import (
 	"golang.org/x/tools/go/ast/inspector"
 )
 
-// AnalyzerExcessiveConfig flags repeated creation of rest.Config/clients in hot paths.
+// AnalyzerExcessiveConfig flags repeated creation of rest.Config/clients in
+// hot paths, including calls of functions that construct a client through
+// other functions, in this or another package.
 var AnalyzerExcessiveConfig = &analysis.Analyzer{
-	Name:     "excessiveconfig",
-	Doc:      "flags repeated rest.Config or client creation in loops or hot paths",
-	Run:      runExcessiveConfig,
-	Requires: []*analysis.Analyzer{insppass.Analyzer},
+	Name:      "excessiveconfig",
+	Doc:       "flags repeated rest.Config or client creation in loops or hot paths",
+	Run:       runExcessiveConfig,
+	Requires:  []*analysis.Analyzer{insppass.Analyzer},
+	FactTypes: []analysis.Fact{(*constructsClientFact)(nil)},
+}
+
+// constructsClientFact marks a function that constructs a Kubernetes client,
+// directly or through the functions it calls. Chain is the call path from the
+// function to the constructor.
+type constructsClientFact struct{ Chain []string }
+
+func (*constructsClientFact) AFact() {}
+
+func (f *constructsClientFact) String() string {
+	return "constructs a client via " + formatCallChain(f.Chain)
 }
 
 func runExcessiveConfig(pass *analysis.Pass) (any, error) {
@@ -37,6 +51,18 @@ func runExcessiveConfig(pass *analysis.Pass) (any, error) {
 		return false
 	}
 
+	imported := func(fn *types.Func) []string {
+		var fact constructsClientFact
+		if pass.ImportObjectFact(fn, &fact) {
+			return fact.Chain
+		}
+		return nil
+	}
+	chains := callChains(pass, func(fn *types.Func) bool { return isKubernetesClientConstructor(fn) }, imported)
+	for fn, chain := range chains {
+		pass.ExportObjectFact(fn, &constructsClientFact{Chain: chain})
+	}
+
 	var currentFunc *ast.FuncDecl
 	loopDepth := 0
 	nodes := []ast.Node{(*ast.FuncDecl)(nil), (*ast.ForStmt)(nil), (*ast.RangeStmt)(nil), (*ast.CallExpr)(nil)}
@@ -64,6 +90,14 @@ func runExcessiveConfig(pass *analysis.Pass) (any, error) {
 				} else if currentFunc != nil && isHotPath(pass, currentFunc) {
 					pass.Reportf(node.Pos(), "client constructed in hot path; create once and reuse")
 				}
+				return true
+			}
+			if chain := chainOfCall(pass, chains, imported, node); chain != nil {
+				if loopDepth > 0 {
+					pass.Reportf(node.Lparen, "call inside loop constructs a client (%s); create once and reuse", formatCallChain(chain))
+				} else if currentFunc != nil && isHotPath(pass, currentFunc) {
+					pass.Reportf(node.Lparen, "call in hot path constructs a client (%s); create once and reuse", formatCallChain(chain))
+				}
 			}
 		}
 		return true
@@ -71,15 +105,33 @@ func runExcessiveConfig(pass *analysis.Pass) (any, error) {
 	return nil, nil
 }
 
+// hotPathFuncs lists additional functions treated as hot paths, set through
+// the hot-paths flag of every analyzer that uses isHotPath. An entry is a
+// function or method name, optionally qualified by receiver type and package
+// path: "Process", "Worker.Process" or "example.com/pkg.Worker.Process".
+var hotPathFuncs stringListFlag
+
+func init() {
+	for _, a := range []*analysis.Analyzer{AnalyzerClientReuse, AnalyzerExcessiveConfig, AnalyzerNotFoundRequeue, AnalyzerUncachedReads} {
+		a.Flags.Var(&hotPathFuncs, "hot-paths", "comma-separated functions to treat as hot paths in addition to ServeHTTP and Reconcile")
+	}
+}
+
 func isHotPath(pass *analysis.Pass, fd *ast.FuncDecl) bool {
 	if fd == nil {
 		return false
 	}
 	obj := pass.TypesInfo.Defs[fd.Name]
+	if obj == nil {
+		return false
+	}
 	sig, ok := obj.Type().(*types.Signature)
 	if !ok {
 		return false
 	}
+	if isConfiguredHotPath(obj, sig) {
+		return true
+	}
 	// Detect HTTP handlers: ServeHTTP(http.ResponseWriter, *http.Request)
 	if fd.Name.Name == "ServeHTTP" {
 		params := sig.Params()
@@ -90,12 +142,32 @@ func isHotPath(pass *analysis.Pass, fd *ast.FuncDecl) bool {
 		}
 	}
 	// Detect controller-runtime reconcilers: Reconcile(...) (reconcile.Result, error)
-	if fd.Name.Name == "Reconcile" {
-		results := sig.Results()
-		if results.Len() >= 1 {
-			if isNamed(deref(results.At(0).Type()), PkgControllerRuntimeReconcile, "Result") {
-				return true
-			}
+	return isReconcileFunc(obj)
+}
+
+// isConfiguredHotPath reports whether fn matches an entry of hotPathFuncs.
+func isConfiguredHotPath(fn types.Object, sig *types.Signature) bool {
+	return matchesFuncList(hotPathFuncs, fn, sig)
+}
+
+// matchesFuncList reports whether fn matches an entry of list, written as in
+// hotPathFuncs.
+func matchesFuncList(list []string, fn types.Object, sig *types.Signature) bool {
+	if len(list) == 0 {
+		return false
+	}
+	name := fn.Name()
+	if recv := sig.Recv(); recv != nil {
+		if n, ok := deref(recv.Type()).(*types.Named); ok {
+			name = n.Obj().Name() + "." + name
+		}
+	}
+	for _, want := range list {
+		if want == name || want == fn.Name() {
+			return true
+		}
+		if fn.Pkg() != nil && want == fn.Pkg().Path()+"."+name {
+			return true
 		}
 	}
 	return false
@@ -1,6 +1,7 @@
 package analyzers
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/amisstea/k8s-client-audit/internal/analyzers/testutil"
@@ -50,3 +51,54 @@ func init(){ _ = NewForConfig(nil) }`
 		t.Fatalf("did not expect diagnostic for init-time creation, got %d", len(diags))
 	}
 }
+
+func TestExcessiveConfig_HelperCalledFromReconcile_FlaggedWithChain(t *testing.T) {
+	helpers := `package clients
+
+import (
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func ForTenant(host string) (*kubernetes.Clientset, error) {
+	return kubernetes.NewForConfig(&rest.Config{Host: host})
+}
+`
+	src := `package p
+
+import (
+	"context"
+
+	"example.com/clients"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type Reconciler struct{ tenants []string }
+
+func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	_, err := clients.ForTenant(req.Name)
+	return reconcile.Result{}, err
+}
+
+func (r *Reconciler) warmUp() {
+	for _, t := range r.tenants {
+		_, _ = clients.ForTenant(t)
+	}
+}
+`
+	res, err := testutil.RunAnalyzerOnPackages(AnalyzerExcessiveConfig, map[string]string{
+		"example.com/clients": helpers,
+		"p":                   src,
+	})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(res.Diagnostics) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %d: %v", len(res.Diagnostics), res.Diagnostics)
+	}
+	for _, d := range res.Diagnostics {
+		if !strings.Contains(d.Message, "clients.ForTenant -> kubernetes.NewForConfig") {
+			t.Fatalf("expected call chain in %q", d.Message)
+		}
+	}
+}
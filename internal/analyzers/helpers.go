@@ -2,10 +2,31 @@ package analyzers
 
 import (
 	"go/ast"
+	"go/token"
 	"go/types"
+	pathpkg "path"
+	"strconv"
 	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
 )
 
+// stringListFlag is a flag.Value holding a comma-separated list of strings.
+type stringListFlag []string
+
+func (l *stringListFlag) String() string { return strings.Join(*l, ",") }
+
+func (l *stringListFlag) Set(s string) error {
+	*l = nil
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			*l = append(*l, part)
+		}
+	}
+	return nil
+}
+
 // calleeIdent returns the identifier for a call expression's callee, handling
 // both simple identifiers and selector expressions. Returns nil if unresolved.
 func calleeIdent(expr ast.Expr) *ast.Ident {
@@ -42,12 +63,23 @@ func isNamed(t types.Type, pkgPath, name string) bool {
 const (
 	PkgControllerRuntimeClient    = "sigs.k8s.io/controller-runtime/pkg/client"
 	PkgControllerRuntimeReconcile = "sigs.k8s.io/controller-runtime/pkg/reconcile"
+	PkgControllerRuntimeManager   = "sigs.k8s.io/controller-runtime/pkg/manager"
+	PkgControllerRuntimeCluster   = "sigs.k8s.io/controller-runtime/pkg/cluster"
+	PkgControllerRuntimeBuilder   = "sigs.k8s.io/controller-runtime/pkg/builder"
+	PkgControllerRuntimeCache     = "sigs.k8s.io/controller-runtime/pkg/cache"
+	PkgControllerRuntimePredicate = "sigs.k8s.io/controller-runtime/pkg/predicate"
+	PkgControllerRuntimeConfig    = "sigs.k8s.io/controller-runtime/pkg/client/config"
+	PkgControllerRuntime          = "sigs.k8s.io/controller-runtime"
 	PkgClientGoDynamic            = "k8s.io/client-go/dynamic"
 	PkgClientGoKubernetes         = "k8s.io/client-go/kubernetes"
 	PkgClientGoRest               = "k8s.io/client-go/rest"
 	PkgMetaV1                     = "k8s.io/apimachinery/pkg/apis/meta/v1"
 	PkgClientGoDiscovery          = "k8s.io/client-go/discovery"
 	PkgClientGoRestMapper         = "k8s.io/client-go/restmapper"
+	PkgClientGoCache              = "k8s.io/client-go/tools/cache"
+	PkgClientGoClientcmd          = "k8s.io/client-go/tools/clientcmd"
+	PkgClientGoRetry              = "k8s.io/client-go/util/retry"
+	PkgAPIErrors                  = "k8s.io/apimachinery/pkg/api/errors"
 )
 
 // isKubernetesPackage returns true if the package path is a known Kubernetes package.
@@ -85,6 +117,46 @@ func isKubernetesClientConstructor(obj types.Object) bool {
 	}
 }
 
+// isManagerConstructor returns true if the object constructs a
+// controller-runtime manager, which builds its clients from the config.
+func isManagerConstructor(obj types.Object) bool {
+	if obj == nil || obj.Pkg() == nil {
+		return false
+	}
+	switch obj.Pkg().Path() {
+	case PkgControllerRuntimeManager:
+		return obj.Name() == "New"
+	case PkgControllerRuntime:
+		return obj.Name() == "NewManager"
+	default:
+		return false
+	}
+}
+
+// isInformerConstructor returns true if the object constructs a shared
+// informer or a shared informer factory.
+func isInformerConstructor(obj types.Object) bool {
+	if obj == nil || obj.Pkg() == nil {
+		return false
+	}
+	name := obj.Name()
+	if !(name == "NewSharedInformerFactory" || name == "NewSharedInformerFactoryWithOptions" || name == "NewSharedIndexInformer" || name == "NewSharedInformer") {
+		return false
+	}
+	pkg := obj.Pkg().Path()
+
+	// Check for Kubernetes informer packages
+	switch {
+	case strings.HasPrefix(pkg, "k8s.io/client-go/informers"):
+		return true
+	case strings.HasPrefix(pkg, "k8s.io/client-go/tools/cache"):
+		return true
+	case strings.HasPrefix(pkg, "sigs.k8s.io/controller-runtime/pkg/cache"):
+		return true
+	}
+	return false
+}
+
 // isKubernetesMethodCall returns true if the object represents a method call from a Kubernetes client
 // with the specified method name(s).
 func isKubernetesMethodCall(obj types.Object, methodNames ...string) bool {
@@ -134,3 +206,139 @@ func isKubernetesType(t types.Type, typeNames ...string) bool {
 func isKubernetesListOptions(t types.Type) bool {
 	return isKubernetesType(t, "ListOptions") || isNamed(t, PkgMetaV1, "ListOptions")
 }
+
+// variadicValues returns the values passed in the variadic parameter of a
+// call, which SSA stores in an array before slicing it, or nil if arg is not
+// such a slice.
+func variadicValues(arg ssa.Value) []ssa.Value {
+	slice, ok := arg.(*ssa.Slice)
+	if !ok || slice.X.Referrers() == nil {
+		return nil
+	}
+	var vals []ssa.Value
+	for _, ref := range *slice.X.Referrers() {
+		ia, ok := ref.(*ssa.IndexAddr)
+		if !ok {
+			continue
+		}
+		for _, r := range *ia.Referrers() {
+			if st, ok := r.(*ssa.Store); ok && st.Addr == ia {
+				vals = append(vals, st.Val)
+			}
+		}
+	}
+	return vals
+}
+
+// isReconcileFunc reports whether obj is a controller-runtime Reconcile
+// method or function: Reconcile(...) (reconcile.Result, error).
+func isReconcileFunc(obj types.Object) bool {
+	if obj == nil || obj.Name() != "Reconcile" {
+		return false
+	}
+	sig, ok := obj.Type().(*types.Signature)
+	if !ok || sig.Results().Len() < 1 {
+		return false
+	}
+	return isNamed(deref(sig.Results().At(0).Type()), PkgControllerRuntimeReconcile, "Result")
+}
+
+// isProgramPackage reports whether pass analyzes the main package of a
+// program. The main packages go test synthesizes, with paths ending in
+// ".test", and packages made only of _test.go files are test binaries.
+func isProgramPackage(pass *analysis.Pass) bool {
+	if pass.Pkg.Name() != "main" || strings.HasSuffix(pass.Pkg.Path(), ".test") {
+		return false
+	}
+	for _, f := range pass.Files {
+		if !strings.HasSuffix(pass.Fset.File(f.Pos()).Name(), "_test.go") {
+			return true
+		}
+	}
+	return false
+}
+
+// ssaCallee returns the function or interface method called by common, or
+// nil for calls of function values and builtins.
+func ssaCallee(common *ssa.CallCommon) *types.Func {
+	if common.IsInvoke() {
+		return common.Method
+	}
+	if f, ok := common.Value.(*ssa.Function); ok {
+		if fn, ok := f.Object().(*types.Func); ok {
+			return fn.Origin()
+		}
+	}
+	return nil
+}
+
+// ssaReceiver returns the receiver of a method call, or nil for calls of
+// functions.
+func ssaReceiver(common *ssa.CallCommon) ssa.Value {
+	if common.IsInvoke() {
+		return common.Value
+	}
+	if common.Signature().Recv() != nil && len(common.Args) > 0 {
+		return common.Args[0]
+	}
+	return nil
+}
+
+// ssaArgs returns the arguments of a call, without the receiver of a method.
+func ssaArgs(common *ssa.CallCommon) []ssa.Value {
+	if !common.IsInvoke() && common.Signature().Recv() != nil && len(common.Args) > 0 {
+		return common.Args[1:]
+	}
+	return common.Args
+}
+
+// enclosingFile returns the file of pass that contains pos.
+func enclosingFile(pass *analysis.Pass, pos token.Pos) *ast.File {
+	for _, f := range pass.Files {
+		if f.FileStart <= pos && pos <= f.FileEnd {
+			return f
+		}
+	}
+	return nil
+}
+
+// importQualifier returns the name by which file refers to the package with
+// the given path and, if file does not import it yet, an edit adding the
+// import. It returns "" for dot imports.
+func importQualifier(file *ast.File, path string) (string, []analysis.TextEdit) {
+	for _, spec := range file.Imports {
+		if p, err := strconv.Unquote(spec.Path.Value); err != nil || p != path {
+			continue
+		}
+		if spec.Name == nil {
+			return pathpkg.Base(path), nil
+		}
+		if spec.Name.Name == "." {
+			return "", nil
+		}
+		if spec.Name.Name != "_" {
+			return spec.Name.Name, nil
+		}
+	}
+	// Add a separate import declaration after the last one, or after the
+	// package clause if there is none.
+	pos := file.Name.End()
+	for _, d := range file.Decls {
+		if gd, ok := d.(*ast.GenDecl); ok && gd.Tok == token.IMPORT {
+			pos = gd.End()
+		}
+	}
+	return pathpkg.Base(path), []analysis.TextEdit{{
+		Pos:     pos,
+		End:     pos,
+		NewText: []byte("\n\nimport " + strconv.Quote(path)),
+	}}
+}
+
+// qualify prefixes name with qualifier, if any.
+func qualify(qualifier, name string) string {
+	if qualifier == "" {
+		return name
+	}
+	return qualifier + "." + name
+}
@@ -0,0 +1,456 @@
+package analyzers
+
+import (
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+	"golang.org/x/tools/go/ssa"
+)
+
+// AnalyzerInformerStart flags shared informer factories that are never
+// started, started repeatedly, or asked for informers after Start.
+//
+// A factory is identified by its constructor call when it stays local to a
+// function, or by the struct field or package-level variable holding it.
+// Informer(), Lister() and InformerFor() requests are ordered against Start()
+// within a function, including requests made by functions of the package it
+// calls after Start. A request after Start is fine if the same resource was
+// requested before it, as for the Lister of an informer registered earlier.
+var AnalyzerInformerStart = &analysis.Analyzer{
+	Name:     "informerstart",
+	Doc:      "flags informer factories never started, started repeatedly, or asked for informers after Start",
+	Run:      runInformerStart,
+	Requires: []*analysis.Analyzer{buildssa.Analyzer},
+}
+
+// factoryEvent is a Start call or an informer request on a factory. For
+// requests, resource is the informer requested as named by informerResource,
+// or "" if unknown.
+type factoryEvent struct {
+	instr    ssa.CallInstruction
+	id       any // *ssa.Call, *types.Var or *ssa.Global
+	resource string
+}
+
+// factoryRequest is an informer of a factory requested by a function.
+type factoryRequest struct {
+	id       any
+	resource string
+}
+
+// factoryUses holds the factory events of a function.
+type factoryUses struct {
+	starts, requests []factoryEvent
+}
+
+func runInformerStart(pass *analysis.Pass) (any, error) {
+	ssaInfo := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+
+	uses := map[*ssa.Function]*factoryUses{}
+	started := map[any]bool{}
+	for _, fn := range ssaInfo.SrcFuncs {
+		u := factoryEvents(fn)
+		uses[fn] = u
+		for _, e := range u.starts {
+			started[e.id] = true
+		}
+	}
+	requestsFrom := sharedFactoryRequests(ssaInfo.SrcFuncs, uses)
+
+	reported := map[any]bool{}
+	for _, fn := range ssaInfo.SrcFuncs {
+		u := uses[fn]
+		for _, s := range u.starts {
+			if inLoop(s.instr.Block()) {
+				pass.Reportf(s.instr.Pos(), "informer factory Start called in a loop; start it once after requesting its informers")
+			} else if prev := startBefore(u, s); prev != nil && !requestedBetween(u, prev, s) {
+				pass.Reportf(s.instr.Pos(), "informer factory started again with no new informers requested; call Start once")
+			}
+		}
+		// Requests made directly and by helpers of this package.
+		requests := append([]factoryEvent(nil), u.requests...)
+		helper := map[ssa.Instruction]bool{}
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				ci, ok := instr.(ssa.CallInstruction)
+				if !ok {
+					continue
+				}
+				callee := ci.Common().StaticCallee()
+				if callee == nil {
+					continue
+				}
+				var reqs []factoryEvent
+				for req := range requestsFrom[callee] {
+					reqs = append(reqs, factoryEvent{ci, req.id, req.resource})
+				}
+				sort.Slice(reqs, func(i, j int) bool {
+					a, b := factoryName(reqs[i].id), factoryName(reqs[j].id)
+					return a < b || a == b && reqs[i].resource < reqs[j].resource
+				})
+				helper[ci] = len(reqs) > 0
+				requests = append(requests, reqs...)
+			}
+		}
+		late := map[ssa.Instruction]bool{}
+		for _, r := range requests {
+			if late[r.instr] || !startedBefore(u, r.id, r.instr) || requestedBeforeStart(u, requests, r) {
+				continue
+			}
+			late[r.instr] = true
+			if helper[r.instr] {
+				pass.Reportf(r.instr.Pos(), "call requests informers from %s after the factory was started; they will never run unless Start is called again", factoryName(r.id))
+			} else {
+				pass.Reportf(r.instr.Pos(), "informer requested after the factory was started; it will never run unless Start is called again")
+			}
+		}
+		for _, r := range u.requests {
+			if started[r.id] || reported[r.id] || !neverStartedElsewhere(r.id) {
+				continue
+			}
+			reported[r.id] = true
+			pass.Reportf(r.instr.Pos(), "informer requested from %s, which is never started; call Start after requesting informers", factoryName(r.id))
+		}
+	}
+	return nil, nil
+}
+
+// factoryEvents collects the Start calls and informer requests of fn.
+func factoryEvents(fn *ssa.Function) *factoryUses {
+	u := &factoryUses{}
+	ids := map[ssa.Value]any{}
+	for changed := true; changed; {
+		changed = false
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				v, ok := instr.(ssa.Value)
+				if !ok {
+					continue
+				}
+				if _, seen := ids[v]; seen {
+					continue
+				}
+				if id := factoryID(v, ids); id != nil {
+					ids[v] = id
+					changed = true
+				}
+			}
+		}
+	}
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			ci, ok := instr.(ssa.CallInstruction)
+			if !ok {
+				continue
+			}
+			callee := ssaCallee(ci.Common())
+			recv := ssaReceiver(ci.Common())
+			if callee == nil || recv == nil || ids[recv] == nil {
+				continue
+			}
+			switch callee.Name() {
+			case "Start":
+				u.starts = append(u.starts, factoryEvent{ci, ids[recv], ""})
+			case "Informer", "Lister", "InformerFor":
+				u.requests = append(u.requests, factoryEvent{ci, ids[recv], informerResource(ci.Common())})
+			}
+		}
+	}
+	return u
+}
+
+// factoryID returns the factory v is, or is derived from, given the ids
+// already known for the other values of the function.
+func factoryID(v ssa.Value, ids map[ssa.Value]any) any {
+	switch v := v.(type) {
+	case *ssa.Call:
+		callee := ssaCallee(&v.Call)
+		if callee == nil {
+			return nil
+		}
+		if isInformerFactoryConstructor(callee) {
+			if !escapes(v) {
+				return v
+			}
+			return storedFactory(v)
+		}
+		if recv := ssaReceiver(&v.Call); recv != nil {
+			return ids[recv]
+		}
+	case *ssa.UnOp:
+		if v.Op != token.MUL || !isInformerFactoryType(v.Type()) {
+			return nil
+		}
+		switch addr := v.X.(type) {
+		case *ssa.FieldAddr:
+			if f := structField(deref(addr.X.Type()), addr.Field); f != nil {
+				return f
+			}
+		case *ssa.Global:
+			return addr
+		}
+	case *ssa.Field:
+		if isInformerFactoryType(v.Type()) {
+			if f := structField(v.X.Type(), v.Field); f != nil {
+				return f
+			}
+		}
+	case *ssa.Phi:
+		for _, e := range v.Edges {
+			if id := ids[e]; id != nil {
+				return id
+			}
+		}
+	case *ssa.ChangeInterface:
+		return ids[v.X]
+	case *ssa.MakeInterface:
+		return ids[v.X]
+	}
+	return nil
+}
+
+// escapes reports whether a locally constructed factory is stored, returned
+// or passed to another function, so that it may be started elsewhere. Such
+// factories are tracked through the field or variable holding them instead.
+func escapes(factory ssa.Value) bool {
+	for _, ref := range *factory.Referrers() {
+		switch ref := ref.(type) {
+		case *ssa.Store, *ssa.Return, *ssa.MakeClosure, *ssa.Send, *ssa.MapUpdate:
+			return true
+		case *ssa.ChangeInterface:
+			if escapes(ref) {
+				return true
+			}
+		case *ssa.MakeInterface:
+			if escapes(ref) {
+				return true
+			}
+		case *ssa.Phi:
+			return true
+		case ssa.CallInstruction:
+			if ssaReceiver(ref.Common()) != factory {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// storedFactory returns the struct field or package-level variable a factory
+// is stored in, or nil.
+func storedFactory(factory ssa.Value) any {
+	for _, ref := range *factory.Referrers() {
+		st, ok := ref.(*ssa.Store)
+		if !ok || st.Val != factory {
+			continue
+		}
+		switch addr := st.Addr.(type) {
+		case *ssa.FieldAddr:
+			if f := structField(deref(addr.X.Type()), addr.Field); f != nil {
+				return f
+			}
+		case *ssa.Global:
+			return addr
+		}
+	}
+	return nil
+}
+
+// isInformerFactoryConstructor reports whether fn constructs a shared
+// informer factory.
+func isInformerFactoryConstructor(fn *types.Func) bool {
+	return fn != nil && isInformerConstructor(fn) && strings.Contains(fn.Name(), "InformerFactory")
+}
+
+// isInformerFactoryType reports whether t is a shared informer factory, as
+// generated by informer-gen for client-go and for custom resources.
+func isInformerFactoryType(t types.Type) bool {
+	n, ok := t.(*types.Named)
+	return ok && strings.HasSuffix(n.Obj().Name(), "SharedInformerFactory")
+}
+
+// sharedFactoryRequests maps functions to the informers they request from
+// fields and package-level factories, directly or through the functions of
+// the package they call.
+func sharedFactoryRequests(funcs []*ssa.Function, uses map[*ssa.Function]*factoryUses) map[*ssa.Function]map[factoryRequest]bool {
+	out := map[*ssa.Function]map[factoryRequest]bool{}
+	add := func(fn *ssa.Function, req factoryRequest) bool {
+		if _, local := req.id.(*ssa.Call); local || out[fn][req] {
+			return false
+		}
+		if out[fn] == nil {
+			out[fn] = map[factoryRequest]bool{}
+		}
+		out[fn][req] = true
+		return true
+	}
+	for _, fn := range funcs {
+		for _, r := range uses[fn].requests {
+			add(fn, factoryRequest{r.id, r.resource})
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, fn := range funcs {
+			for _, b := range fn.Blocks {
+				for _, instr := range b.Instrs {
+					ci, ok := instr.(ssa.CallInstruction)
+					if !ok {
+						continue
+					}
+					callee := ci.Common().StaticCallee()
+					if callee == nil || callee == fn {
+						continue
+					}
+					for req := range out[callee] {
+						changed = add(fn, req) || changed
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+// startedBefore reports whether a Start on id executes before instr on every
+// path to it, with no Start following instr to pick up late informers.
+func startedBefore(u *factoryUses, id any, instr ssa.Instruction) bool {
+	before, after := false, false
+	for _, s := range u.starts {
+		if s.id != id || s.instr == instr {
+			continue
+		}
+		if dominates(s.instr, instr) {
+			before = true
+		} else if dominates(instr, s.instr) {
+			after = true
+		}
+	}
+	return before && !after
+}
+
+// requestedBeforeStart reports whether the informer of r, when known, is
+// also among requests before a Start that precedes r.
+func requestedBeforeStart(u *factoryUses, requests []factoryEvent, r factoryEvent) bool {
+	if r.resource == "" {
+		return false
+	}
+	for _, s := range u.starts {
+		if s.id != r.id || !dominates(s.instr, r.instr) {
+			continue
+		}
+		for _, q := range requests {
+			if q.id == r.id && q.resource == r.resource && dominates(q.instr, s.instr) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// startBefore returns a Start on the same factory that executes before s on
+// every path to it, or nil.
+func startBefore(u *factoryUses, s factoryEvent) ssa.Instruction {
+	for _, prev := range u.starts {
+		if prev.id == s.id && prev.instr != s.instr && dominates(prev.instr, s.instr) {
+			return prev.instr
+		}
+	}
+	return nil
+}
+
+// requestedBetween reports whether an informer of s's factory is requested
+// after prev and before s.
+func requestedBetween(u *factoryUses, prev ssa.Instruction, s factoryEvent) bool {
+	for _, r := range u.requests {
+		if r.id == s.id && dominates(prev, r.instr) && dominates(r.instr, s.instr) {
+			return true
+		}
+	}
+	return false
+}
+
+// neverStartedElsewhere reports whether a factory with no Start in the
+// package cannot be started by other packages either.
+func neverStartedElsewhere(id any) bool {
+	switch id := id.(type) {
+	case *ssa.Call:
+		return true
+	case *types.Var:
+		return !id.Exported()
+	case *ssa.Global:
+		return !token.IsExported(id.Name())
+	}
+	return false
+}
+
+// factoryName describes a factory for diagnostics.
+func factoryName(id any) string {
+	switch id := id.(type) {
+	case *types.Var:
+		return "field " + id.Name()
+	case *ssa.Global:
+		return "variable " + id.Name()
+	}
+	return "the factory"
+}
+
+// informerResource names the resource of an informer request by the methods
+// leading to it from the factory, like "Core().V1().Pods()", or by the object
+// type passed to InformerFor.
+func informerResource(common *ssa.CallCommon) string {
+	callee := ssaCallee(common)
+	if callee == nil {
+		return ""
+	}
+	if callee.Name() == "InformerFor" {
+		if args := ssaArgs(common); len(args) > 0 {
+			if mi, ok := args[0].(*ssa.MakeInterface); ok {
+				return types.TypeString(mi.X.Type(), (*types.Package).Name)
+			}
+		}
+		return ""
+	}
+	var names []string
+	for recv := ssaReceiver(common); recv != nil; {
+		call, ok := recv.(*ssa.Call)
+		if !ok {
+			break
+		}
+		c := ssaCallee(&call.Call)
+		if c == nil || isInformerFactoryConstructor(c) {
+			break
+		}
+		names = append([]string{c.Name() + "()"}, names...)
+		recv = ssaReceiver(&call.Call)
+	}
+	return strings.Join(names, ".")
+}
+
+// dominates reports whether a executes before b on every path to b.
+func dominates(a, b ssa.Instruction) bool {
+	return anyDominates([]ssa.Instruction{a}, b)
+}
+
+// inLoop reports whether b is part of a cycle of the control flow graph.
+func inLoop(b *ssa.BasicBlock) bool {
+	seen := map[*ssa.BasicBlock]bool{}
+	stack := append([]*ssa.BasicBlock(nil), b.Succs...)
+	for len(stack) > 0 {
+		next := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if next == b {
+			return true
+		}
+		if seen[next] {
+			continue
+		}
+		seen[next] = true
+		stack = append(stack, next.Succs...)
+	}
+	return false
+}
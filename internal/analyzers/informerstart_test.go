@@ -0,0 +1,165 @@
+package analyzers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/amisstea/k8s-client-audit/internal/analyzers/testutil"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+const informerStartImports = `package p
+
+import (
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	_ listers.PodLister
+	_ cache.SharedIndexInformer
+)
+`
+
+func runInformerStartOnSrc(t *testing.T, src string) []analysis.Diagnostic {
+	t.Helper()
+	res, err := testutil.RunAnalyzerOnStubbedSrc(AnalyzerInformerStart, src)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	return res.Diagnostics
+}
+
+func TestInformerStart_RequestBeforeStart_NoDiag(t *testing.T) {
+	src := informerStartImports + `
+func run(cs kubernetes.Interface, stop chan struct{}) listers.PodLister {
+	factory := informers.NewSharedInformerFactory(cs, 0)
+	lister := factory.Core().V1().Pods().Lister()
+	factory.Start(stop)
+	factory.WaitForCacheSync(stop)
+	return lister
+}`
+	if diags := runInformerStartOnSrc(t, src); len(diags) != 0 {
+		t.Fatalf("expected 0 diagnostics, got %v", diags)
+	}
+}
+
+func TestInformerStart_ListerOfRegisteredInformerAfterStart_NoDiag(t *testing.T) {
+	src := informerStartImports + `
+func run(cs kubernetes.Interface, stop chan struct{}) listers.PodLister {
+	factory := informers.NewSharedInformerFactory(cs, 0)
+	pods := factory.Core().V1().Pods()
+	pods.Informer()
+	factory.Start(stop)
+	factory.WaitForCacheSync(stop)
+	return pods.Lister()
+}`
+	if diags := runInformerStartOnSrc(t, src); len(diags) != 0 {
+		t.Fatalf("expected 0 diagnostics, got %v", diags)
+	}
+}
+
+func TestInformerStart_LateRequest_Flagged(t *testing.T) {
+	src := informerStartImports + `
+func run(cs kubernetes.Interface, stop chan struct{}) cache.SharedIndexInformer {
+	factory := informers.NewSharedInformerFactory(cs, 0)
+	factory.Start(stop)
+	return factory.Core().V1().Pods().Informer()
+}
+
+func rescued(cs kubernetes.Interface, stop chan struct{}) cache.SharedIndexInformer {
+	factory := informers.NewSharedInformerFactory(cs, 0)
+	factory.Start(stop)
+	informer := factory.Core().V1().Pods().Informer()
+	factory.Start(stop)
+	return informer
+}`
+	diags := runInformerStartOnSrc(t, src)
+	if len(diags) != 1 || !strings.Contains(diags[0].Message, "after the factory was started") {
+		t.Fatalf("expected one late-request diagnostic, got %v", diags)
+	}
+}
+
+func TestInformerStart_RepeatedStart_Flagged(t *testing.T) {
+	src := informerStartImports + `
+func loop(cs kubernetes.Interface, stops []chan struct{}) {
+	factory := informers.NewSharedInformerFactory(cs, 0)
+	_ = factory.Core().V1().Pods().Lister()
+	for _, stop := range stops {
+		factory.Start(stop)
+	}
+}
+
+func twice(cs kubernetes.Interface, stop chan struct{}) {
+	factory := informers.NewSharedInformerFactory(cs, 0)
+	_ = factory.Core().V1().Pods().Lister()
+	factory.Start(stop)
+	factory.Start(stop)
+}`
+	diags := runInformerStartOnSrc(t, src)
+	if len(diags) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %v", diags)
+	}
+	if !strings.Contains(diags[0].Message, "in a loop") || !strings.Contains(diags[1].Message, "started again") {
+		t.Fatalf("unexpected messages: %v", diags)
+	}
+}
+
+func TestInformerStart_NeverStarted_Flagged(t *testing.T) {
+	src := informerStartImports + `
+func run(cs kubernetes.Interface) listers.PodLister {
+	factory := informers.NewSharedInformerFactory(cs, 0)
+	return factory.Core().V1().Pods().Lister()
+}`
+	diags := runInformerStartOnSrc(t, src)
+	if len(diags) != 1 || !strings.Contains(diags[0].Message, "never started") {
+		t.Fatalf("expected one never-started diagnostic, got %v", diags)
+	}
+}
+
+func TestInformerStart_StructField_LateRequestThroughHelper(t *testing.T) {
+	src := informerStartImports + `
+type Controller struct {
+	factory informers.SharedInformerFactory
+	pods    listers.PodLister
+}
+
+func New(cs kubernetes.Interface) *Controller {
+	return &Controller{factory: informers.NewSharedInformerFactory(cs, 0)}
+}
+
+func (c *Controller) Run(stop chan struct{}) {
+	c.factory.Start(stop)
+	c.register()
+	c.factory.WaitForCacheSync(stop)
+}
+
+func (c *Controller) register() {
+	c.pods = c.factory.Core().V1().Pods().Lister()
+}`
+	diags := runInformerStartOnSrc(t, src)
+	if len(diags) != 1 || !strings.Contains(diags[0].Message, "field factory") {
+		t.Fatalf("expected one diagnostic for the helper call, got %v", diags)
+	}
+}
+
+func TestInformerStart_StructFieldNeverStarted_Flagged(t *testing.T) {
+	src := informerStartImports + `
+type Controller struct {
+	factory informers.SharedInformerFactory
+	pods    listers.PodLister
+}
+
+func New(cs kubernetes.Interface) *Controller {
+	c := &Controller{factory: informers.NewSharedInformerFactory(cs, 0)}
+	c.pods = c.factory.Core().V1().Pods().Lister()
+	return c
+}`
+	diags := runInformerStartOnSrc(t, src)
+	if len(diags) != 1 || !strings.Contains(diags[0].Message, "never started") {
+		t.Fatalf("expected one never-started diagnostic, got %v", diags)
+	}
+}
@@ -5,22 +5,31 @@ import (
 	"go/constant"
 
 	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
 	insppass "golang.org/x/tools/go/analysis/passes/inspect"
 	"golang.org/x/tools/go/ast/inspector"
 )
 
-// AnalyzerLargePageSizes flags ListOptions with very large Limit values.
+// AnalyzerLargePageSizes flags ListOptions with very large Limit values,
+// including limits set from named constants, variables, struct fields, flag
+// defaults and helper functions returning constants.
 var AnalyzerLargePageSizes = &analysis.Analyzer{
 	Name:     "largepages",
 	Doc:      "flags excessively large page sizes in list calls",
 	Run:      runLargePages,
-	Requires: []*analysis.Analyzer{insppass.Analyzer},
+	Requires: []*analysis.Analyzer{insppass.Analyzer, buildssa.Analyzer},
 }
 
-const defaultLargePageThreshold = 1000
+// largePageThreshold is the smallest ListOptions.Limit reported as too large.
+var largePageThreshold int64 = 1000
+
+func init() {
+	AnalyzerLargePageSizes.Flags.Int64Var(&largePageThreshold, "threshold", largePageThreshold, "report ListOptions.Limit values at or above this page size")
+}
 
 func runLargePages(pass *analysis.Pass) (any, error) {
 	insp := pass.ResultOf[insppass.Analyzer].(*inspector.Inspector)
+	consts := newConstTracer(pass)
 
 	nodes := []ast.Node{(*ast.CallExpr)(nil)}
 	insp.Nodes(nodes, func(n ast.Node, push bool) bool {
@@ -51,9 +60,9 @@ func runLargePages(pass *analysis.Pass) (any, error) {
 					for _, el := range cl.Elts {
 						if kv, ok := el.(*ast.KeyValueExpr); ok {
 							if id, ok := kv.Key.(*ast.Ident); ok && id.Name == "Limit" {
-								if tv := pass.TypesInfo.Types[kv.Value]; tv.Value != nil {
-									if v, ok := constant.Int64Val(tv.Value); ok {
-										if v >= defaultLargePageThreshold {
+								if c := consts.exprValue(kv.Value, kv.Colon); c != nil && c.Kind() == constant.Int {
+									if v, ok := constant.Int64Val(c); ok {
+										if v >= largePageThreshold {
 											pass.Reportf(id.Pos(), "Kubernetes ListOptions.Limit is very large (%d); use reasonable page sizes", v)
 										}
 									}
@@ -8,49 +8,73 @@ import (
 	"golang.org/x/tools/go/analysis"
 )
 
-func runLargePagesAnalyzerOnSrc(t *testing.T, src string, spoof bool) []analysis.Diagnostic {
+func runLargePagesAnalyzerOnSrc(t *testing.T, src string) []analysis.Diagnostic {
 	t.Helper()
-	var diags []analysis.Diagnostic
-	var err error
-	if spoof {
-		diags, err = testutil.RunAnalyzerOnSrc(AnalyzerLargePageSizes, src, testutil.SpoofCommonK8s, testutil.SpoofListOptionsType)
-	} else {
-		diags, err = testutil.RunAnalyzerOnSrc(AnalyzerLargePageSizes, src)
-	}
+	res, err := testutil.RunAnalyzerOnStubbedSrc(AnalyzerLargePageSizes, src)
 	if err != nil {
 		t.Fatalf("run: %v", err)
 	}
-	return diags
+	return res.Diagnostics
 }
 
+const largePagesImports = `package a
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+var _ = context.TODO
+`
+
 func TestLargePages_LimitLarge_Flagged(t *testing.T) {
-	src := `package a
-type ListOptions struct{ Limit int64 }
-type IFace interface{ List(x any, opts ListOptions) error }
-func f(c IFace){ _ = c.List(nil, ListOptions{Limit:2000}) }`
-	diags := runLargePagesAnalyzerOnSrc(t, src, true) // spoof as Kubernetes types
+	src := largePagesImports + `
+func f(ctx context.Context, c corev1client.PodInterface) { _, _ = c.List(ctx, metav1.ListOptions{Limit: 2000}) }`
+	diags := runLargePagesAnalyzerOnSrc(t, src)
 	if len(diags) == 0 {
 		t.Fatalf("expected diagnostic for large Kubernetes page size")
 	}
 }
 
 func TestLargePages_LimitReasonable_NoDiag(t *testing.T) {
-	src := `package a
-type ListOptions struct{ Limit int64 }
-type IFace interface{ List(x any, opts ListOptions) error }
-func f(c IFace){ _ = c.List(nil, ListOptions{Limit:100}) }`
-	diags := runLargePagesAnalyzerOnSrc(t, src, true) // spoof as Kubernetes types
+	src := largePagesImports + `
+func f(ctx context.Context, c corev1client.PodInterface) { _, _ = c.List(ctx, metav1.ListOptions{Limit: 100}) }`
+	diags := runLargePagesAnalyzerOnSrc(t, src)
 	if len(diags) != 0 {
 		t.Fatalf("did not expect diagnostic for reasonable page size")
 	}
 }
 
+func TestLargePages_ResolvedLimit_Flagged(t *testing.T) {
+	src := largePagesImports + `
+const pageSize = 1_000_000
+
+type Options struct{ PageSize int64 }
+
+func defaultPageSize() int64 { return pageSize / 2 }
+
+func f(ctx context.Context, c corev1client.PodInterface, o Options, n int64) {
+	_, _ = c.List(ctx, metav1.ListOptions{Limit: pageSize})
+	_, _ = c.List(ctx, metav1.ListOptions{Limit: defaultPageSize()})
+	_, _ = c.List(ctx, metav1.ListOptions{Limit: o.PageSize})
+	_, _ = c.List(ctx, metav1.ListOptions{Limit: n})
+}
+
+var defaults = Options{PageSize: 5000}`
+	diags := runLargePagesAnalyzerOnSrc(t, src)
+	if len(diags) != 3 {
+		t.Fatalf("expected 3 diagnostics for resolved page sizes, got %d", len(diags))
+	}
+}
+
 func TestLargePages_NonKubernetesListOptions_NoDiag(t *testing.T) {
 	src := `package a
 type ListOptions struct{ Limit int64 }
 type DatabaseClient interface{ List(opts ListOptions) error }
 func f(c DatabaseClient){ _ = c.List(ListOptions{Limit:2000}) }`
-	diags := runLargePagesAnalyzerOnSrc(t, src, false) // don't spoof - should be treated as non-Kubernetes
+	diags := runLargePagesAnalyzerOnSrc(t, src)
 	if len(diags) != 0 {
 		t.Fatalf("expected 0 diagnostics for non-Kubernetes ListOptions, got %d", len(diags))
 	}
@@ -61,8 +85,18 @@ func TestLargePages_NonKubernetesList_NoDiag(t *testing.T) {
 type ListOptions struct{ Limit int64 }
 type APIClient interface{ List(opts ListOptions) error }
 func f(c APIClient){ _ = c.List(ListOptions{Limit:2000}) }`
-	diags := runLargePagesAnalyzerOnSrc(t, src, false) // don't spoof - should be treated as non-Kubernetes
+	diags := runLargePagesAnalyzerOnSrc(t, src)
 	if len(diags) != 0 {
 		t.Fatalf("expected 0 diagnostics for non-Kubernetes List calls, got %d", len(diags))
 	}
 }
+
+func TestLargePages_CustomThreshold_Flagged(t *testing.T) {
+	testutil.SetAnalyzerFlag(t, AnalyzerLargePageSizes, "threshold", "50")
+	src := largePagesImports + `
+func f(ctx context.Context, c corev1client.PodInterface) { _, _ = c.List(ctx, metav1.ListOptions{Limit: 100}) }`
+	diags := runLargePagesAnalyzerOnSrc(t, src)
+	if len(diags) == 0 {
+		t.Fatalf("expected diagnostic when Limit exceeds the configured threshold")
+	}
+}
@@ -9,12 +9,30 @@ import (
 	"golang.org/x/tools/go/ast/inspector"
 )
 
-// AnalyzerListInLoop flags List/Watch calls inside loops.
+// AnalyzerListInLoop flags List/Watch calls inside loops, including calls of
+// functions that list or watch through other functions, in this or another
+// package.
 var AnalyzerListInLoop = &analysis.Analyzer{
-	Name:     "listinloop",
-	Doc:      "flags List/Watch calls inside loops (prefer informers/cache)",
-	Run:      runListInLoop,
-	Requires: []*analysis.Analyzer{insppass.Analyzer},
+	Name:      "listinloop",
+	Doc:       "flags List/Watch calls inside loops (prefer informers/cache)",
+	Run:       runListInLoop,
+	Requires:  []*analysis.Analyzer{insppass.Analyzer},
+	FactTypes: []analysis.Fact{(*listsFact)(nil)},
+}
+
+// listsFact marks a function that performs a Kubernetes API List or Watch,
+// directly or through the functions it calls. Chain is the call path from
+// the function to the List or Watch.
+type listsFact struct{ Chain []string }
+
+func (*listsFact) AFact() {}
+
+func (f *listsFact) String() string { return "lists via " + formatCallChain(f.Chain) }
+
+// isAPIListOrWatch reports whether fn is the List or Watch method of a
+// Kubernetes client.
+func isAPIListOrWatch(fn *types.Func) bool {
+	return isKubernetesMethodCall(fn, "List", "Watch")
 }
 
 func runListInLoop(pass *analysis.Pass) (any, error) {
@@ -36,6 +54,19 @@ func runListInLoop(pass *analysis.Pass) (any, error) {
 		_ = pkg
 		return true
 	}
+
+	imported := func(fn *types.Func) []string {
+		var fact listsFact
+		if pass.ImportObjectFact(fn, &fact) {
+			return fact.Chain
+		}
+		return nil
+	}
+	chains := callChains(pass, isAPIListOrWatch, imported)
+	for fn, chain := range chains {
+		pass.ExportObjectFact(fn, &listsFact{Chain: chain})
+	}
+
 	insp.Nodes(nodes, func(n ast.Node, push bool) bool {
 		switch x := n.(type) {
 		case *ast.ForStmt, *ast.RangeStmt:
@@ -52,12 +83,14 @@ func runListInLoop(pass *analysis.Pass) (any, error) {
 			if id := calleeIdent(x.Fun); id != nil {
 				if isKubeListOrWatch(pass.TypesInfo.Uses[id]) {
 					pass.Reportf(id.Pos(), "List/Watch call inside loop; prefer informers/cache or move calls outside loops")
+					return true
 				}
 			}
+			if chain := chainOfCall(pass, chains, imported, x); chain != nil {
+				pass.Reportf(x.Lparen, "call inside loop performs List/Watch (%s); prefer informers/cache or move calls outside loops", formatCallChain(chain))
+			}
 		}
 		return true
 	})
 	return nil, nil
 }
-
-// calleeIdent reused from unboundedqueue.go
@@ -1,6 +1,7 @@
 package analyzers
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/amisstea/k8s-client-audit/internal/analyzers/testutil"
@@ -36,3 +37,111 @@ func f(c C){ _ = c.List() }`
 		t.Fatalf("expected 0 diagnostics, got %d", len(diags))
 	}
 }
+
+const listHelpersSrc = `package helpers
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+func FetchPods(ctx context.Context, cs kubernetes.Interface, ns string) ([]corev1.Pod, error) {
+	return listPods(ctx, cs, ns)
+}
+
+func listPods(ctx context.Context, cs kubernetes.Interface, ns string) ([]corev1.Pod, error) {
+	list, err := cs.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+func Namespaces() []string { return []string{"a", "b"} }
+`
+
+func TestListInLoop_HelperInOtherPackage_FlaggedWithChain(t *testing.T) {
+	src := `package p
+
+import (
+	"context"
+
+	"example.com/helpers"
+	"k8s.io/client-go/kubernetes"
+)
+
+func sync(ctx context.Context, cs kubernetes.Interface) {
+	for _, ns := range helpers.Namespaces() {
+		_, _ = helpers.FetchPods(ctx, cs, ns)
+	}
+}
+`
+	res, err := testutil.RunAnalyzerOnPackages(AnalyzerListInLoop, map[string]string{
+		"example.com/helpers": listHelpersSrc,
+		"p":                   src,
+	})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(res.Diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %d: %v", len(res.Diagnostics), res.Diagnostics)
+	}
+	want := "helpers.FetchPods -> helpers.listPods -> v1.PodInterface.List"
+	if msg := res.Diagnostics[0].Message; !strings.Contains(msg, want) {
+		t.Fatalf("expected call chain %q in %q", want, msg)
+	}
+}
+
+func TestListInLoop_HelperInSamePackage_Flagged(t *testing.T) {
+	src := `package p
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func countAll(ctx context.Context, c client.Client, lists []client.ObjectList) {
+	for _, l := range lists {
+		load(ctx, c, l)
+	}
+}
+
+func load(ctx context.Context, c client.Client, l client.ObjectList) {
+	_ = c.List(ctx, l)
+}
+`
+	res, err := testutil.RunAnalyzerOnStubbedSrc(AnalyzerListInLoop, src)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(res.Diagnostics) != 1 || !strings.Contains(res.Diagnostics[0].Message, "p.load -> client.Reader.List") {
+		t.Fatalf("expected one diagnostic with the call chain, got %v", res.Diagnostics)
+	}
+}
+
+func TestListInLoop_HelperWithoutList_NoDiag(t *testing.T) {
+	src := `package p
+
+import "example.com/helpers"
+
+func f() {
+	for i := 0; i < 3; i++ {
+		_ = helpers.Namespaces()
+	}
+}
+`
+	res, err := testutil.RunAnalyzerOnPackages(AnalyzerListInLoop, map[string]string{
+		"example.com/helpers": listHelpersSrc,
+		"p":                   src,
+	})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(res.Diagnostics) != 0 {
+		t.Fatalf("expected 0 diagnostics, got %v", res.Diagnostics)
+	}
+}
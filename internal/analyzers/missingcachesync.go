@@ -0,0 +1,204 @@
+package analyzers
+
+import (
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+	"golang.org/x/tools/go/ssa"
+)
+
+// AnalyzerMissingCacheSync flags reads from listers and informer stores that
+// are not preceded by a wait for the informer caches to sync.
+//
+// Informers and factories created in a function are followed through the
+// values derived from them (Core().V1().Pods().Lister() and the like). A read
+// of a lister, store or indexer is reported unless a WaitForCacheSync call,
+// or a call of a function that waits, dominates it.
+var AnalyzerMissingCacheSync = &analysis.Analyzer{
+	Name:      "missingcachesync",
+	Doc:       "flags lister and informer store reads not preceded by WaitForCacheSync",
+	Run:       runMissingCacheSync,
+	Requires:  []*analysis.Analyzer{buildssa.Analyzer},
+	FactTypes: []analysis.Fact{(*waitsForCacheSyncFact)(nil)},
+}
+
+// waitsForCacheSyncFact marks a function that waits for informer caches to
+// sync, directly or through the functions it calls. Chain is the call path
+// from the function to the wait.
+type waitsForCacheSyncFact struct{ Chain []string }
+
+func (*waitsForCacheSyncFact) AFact() {}
+
+func (f *waitsForCacheSyncFact) String() string {
+	return "waits for cache sync via " + formatCallChain(f.Chain)
+}
+
+func runMissingCacheSync(pass *analysis.Pass) (any, error) {
+	ssaInfo := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+
+	imported := func(fn *types.Func) []string {
+		var fact waitsForCacheSyncFact
+		if pass.ImportObjectFact(fn, &fact) {
+			return fact.Chain
+		}
+		return nil
+	}
+	chains := callChains(pass, isCacheSyncWait, imported)
+	for fn, chain := range chains {
+		pass.ExportObjectFact(fn, &waitsForCacheSyncFact{Chain: chain})
+	}
+	waits := func(common *ssa.CallCommon) bool {
+		callee := ssaCallee(common)
+		if callee == nil {
+			return false
+		}
+		if isCacheSyncWait(callee) || chains[callee] != nil {
+			return true
+		}
+		return callee.Pkg() != nil && callee.Pkg() != pass.Pkg && imported(callee) != nil
+	}
+
+	for _, fn := range ssaInfo.SrcFuncs {
+		derived := informerValues(fn)
+		if len(derived) == 0 {
+			continue
+		}
+		var syncs []ssa.Instruction
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				if ci, ok := instr.(ssa.CallInstruction); ok && waits(ci.Common()) {
+					syncs = append(syncs, instr)
+				}
+			}
+		}
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				ci, ok := instr.(ssa.CallInstruction)
+				if !ok {
+					continue
+				}
+				common := ci.Common()
+				callee := ssaCallee(common)
+				recv := ssaReceiver(common)
+				if callee == nil || recv == nil || !derived[recv].cache || !isCacheRead(callee.Name()) {
+					continue
+				}
+				if !anyDominates(syncs, instr) {
+					pass.Reportf(ci.Pos(), "informer cache read with %s before WaitForCacheSync; wait for the caches to sync after Start before the first read", callee.Name())
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// informerValue describes a value derived from an informer or factory.
+type informerValue struct {
+	// cache is set for listers, stores and indexers, whose reads are served
+	// from the informer cache.
+	cache bool
+}
+
+// informerValues returns the values of fn derived from informers and
+// informer factories constructed in fn.
+func informerValues(fn *ssa.Function) map[ssa.Value]informerValue {
+	derived := map[ssa.Value]informerValue{}
+	set := func(v ssa.Value, iv informerValue) bool {
+		if old, ok := derived[v]; ok && (old.cache || !iv.cache) {
+			return false
+		}
+		derived[v] = iv
+		return true
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				v, ok := instr.(ssa.Value)
+				if !ok {
+					continue
+				}
+				switch v := v.(type) {
+				case *ssa.Call:
+					callee := ssaCallee(&v.Call)
+					if callee == nil {
+						continue
+					}
+					if isInformerConstructor(callee) {
+						changed = set(v, informerValue{}) || changed
+					} else if recv := ssaReceiver(&v.Call); recv != nil {
+						if iv, ok := derived[recv]; ok {
+							switch callee.Name() {
+							case "Lister", "GetStore", "GetIndexer":
+								iv.cache = true
+							}
+							changed = set(v, iv) || changed
+						}
+					}
+				case *ssa.Phi:
+					for _, e := range v.Edges {
+						if iv, ok := derived[e]; ok {
+							changed = set(v, iv) || changed
+						}
+					}
+				case *ssa.ChangeInterface:
+					if iv, ok := derived[v.X]; ok {
+						changed = set(v, iv) || changed
+					}
+				case *ssa.MakeInterface:
+					if iv, ok := derived[v.X]; ok {
+						changed = set(v, iv) || changed
+					}
+				}
+			}
+		}
+	}
+	return derived
+}
+
+// isCacheRead reports whether a lister, store or indexer method reads from
+// the cache.
+func isCacheRead(name string) bool {
+	switch name {
+	case "Get", "List", "GetByKey", "ListKeys", "ByIndex":
+		return true
+	}
+	return false
+}
+
+// isCacheSyncWait reports whether fn waits for client-go or controller-runtime
+// informer caches to sync.
+func isCacheSyncWait(fn *types.Func) bool {
+	if fn == nil || fn.Pkg() == nil {
+		return false
+	}
+	if fn.Name() != "WaitForCacheSync" && fn.Name() != "WaitForNamedCacheSync" {
+		return false
+	}
+	pkg := fn.Pkg().Path()
+	return strings.HasPrefix(pkg, "k8s.io/client-go/") || strings.HasPrefix(pkg, "sigs.k8s.io/controller-runtime/")
+}
+
+// anyDominates reports whether one of instrs executes before instr on every
+// path to it.
+func anyDominates(instrs []ssa.Instruction, instr ssa.Instruction) bool {
+	for _, d := range instrs {
+		if d.Block() != instr.Block() {
+			if d.Block().Dominates(instr.Block()) {
+				return true
+			}
+			continue
+		}
+		for _, i := range instr.Block().Instrs {
+			if i == d {
+				return true
+			}
+			if i == instr {
+				break
+			}
+		}
+	}
+	return false
+}
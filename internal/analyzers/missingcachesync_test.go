@@ -0,0 +1,106 @@
+package analyzers
+
+import (
+	"testing"
+
+	"github.com/amisstea/k8s-client-audit/internal/analyzers/testutil"
+)
+
+const cacheSyncImports = `package p
+
+import (
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	_ = labels.Everything
+	_ = cache.WaitForCacheSync
+)
+`
+
+func runMissingCacheSyncOnSrc(t *testing.T, src string) int {
+	t.Helper()
+	res, err := testutil.RunAnalyzerOnStubbedSrc(AnalyzerMissingCacheSync, src)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	return len(res.Diagnostics)
+}
+
+func TestMissingCacheSync_ReadAfterStart_Flagged(t *testing.T) {
+	src := cacheSyncImports + `
+func run(cs kubernetes.Interface, stop chan struct{}) error {
+	factory := informers.NewSharedInformerFactory(cs, 0)
+	pods := factory.Core().V1().Pods().Lister()
+	factory.Start(stop)
+	_, err := pods.Pods("ns").Get("a")
+	return err
+}`
+	if n := runMissingCacheSyncOnSrc(t, src); n != 1 {
+		t.Fatalf("expected 1 diagnostic, got %d", n)
+	}
+}
+
+func TestMissingCacheSync_WaitDominatesRead_NoDiag(t *testing.T) {
+	src := cacheSyncImports + `
+func run(cs kubernetes.Interface, stop chan struct{}) error {
+	factory := informers.NewSharedInformerFactoryWithOptions(cs, 0)
+	informer := factory.Core().V1().Pods().Informer()
+	pods := factory.Core().V1().Pods().Lister()
+	factory.Start(stop)
+	if !cache.WaitForCacheSync(stop, informer.HasSynced) {
+		return nil
+	}
+	_, err := pods.List(labels.Everything())
+	_ = informer.GetStore().List()
+	return err
+}
+
+func runFactoryWait(cs kubernetes.Interface, stop chan struct{}) {
+	factory := informers.NewSharedInformerFactory(cs, 0)
+	store := factory.Core().V1().Pods().Informer().GetIndexer()
+	factory.Start(stop)
+	factory.WaitForCacheSync(stop)
+	_ = store.ListKeys()
+}`
+	if n := runMissingCacheSyncOnSrc(t, src); n != 0 {
+		t.Fatalf("expected 0 diagnostics, got %d", n)
+	}
+}
+
+func TestMissingCacheSync_WaitOnOneBranchOnly_Flagged(t *testing.T) {
+	src := cacheSyncImports + `
+func run(cs kubernetes.Interface, stop chan struct{}, wait bool) {
+	factory := informers.NewSharedInformerFactory(cs, 0)
+	informer := factory.Core().V1().Pods().Informer()
+	factory.Start(stop)
+	if wait {
+		factory.WaitForCacheSync(stop)
+	}
+	_ = informer.GetStore().List()
+}`
+	if n := runMissingCacheSyncOnSrc(t, src); n != 1 {
+		t.Fatalf("expected 1 diagnostic, got %d", n)
+	}
+}
+
+func TestMissingCacheSync_WaitInHelper_NoDiag(t *testing.T) {
+	src := cacheSyncImports + `
+func run(cs kubernetes.Interface, stop chan struct{}) {
+	factory := informers.NewSharedInformerFactory(cs, 0)
+	informer := factory.Core().V1().Pods().Informer()
+	factory.Start(stop)
+	waitSynced(stop, informer.HasSynced)
+	_ = informer.GetStore().List()
+}
+
+func waitSynced(stop chan struct{}, synced cache.InformerSynced) {
+	cache.WaitForNamedCacheSync("pods", stop, synced)
+}`
+	if n := runMissingCacheSyncOnSrc(t, src); n != 0 {
+		t.Fatalf("expected 0 diagnostics, got %d", n)
+	}
+}
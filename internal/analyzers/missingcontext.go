@@ -2,50 +2,313 @@ package analyzers
 
 import (
 	"go/ast"
+	"go/token"
+	"go/types"
 
 	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
 	insppass "golang.org/x/tools/go/analysis/passes/inspect"
 	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/ssa"
 )
 
-// AnalyzerMissingContext flags client calls that pass context.Background/TODO instead of a propagated context.
+// AnalyzerMissingContext flags client calls whose context is derived from
+// context.Background/TODO although a propagated context is available.
+//
+// The check works on SSA: the value passed as the context argument of any
+// client-go or controller-runtime call is traced through local variables,
+// package-level variables, closures and context.With* wrappers. A finding is
+// only reported when the enclosing function, or one of its callers in the
+// package, has a context.Context parameter that could have been used instead.
 var AnalyzerMissingContext = &analysis.Analyzer{
 	Name:     "missingcontext",
 	Doc:      "flags client calls using context.Background/TODO instead of propagated context",
 	Run:      runMissingContext,
-	Requires: []*analysis.Analyzer{insppass.Analyzer},
+	Requires: []*analysis.Analyzer{insppass.Analyzer, buildssa.Analyzer},
 }
 
 func runMissingContext(pass *analysis.Pass) (any, error) {
 	insp := pass.ResultOf[insppass.Analyzer].(*inspector.Inspector)
+	ssaInfo := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
 
-	nodes := []ast.Node{(*ast.CallExpr)(nil)}
-	insp.Nodes(nodes, func(n ast.Node, push bool) bool {
-		if !push {
-			return true
+	// Index call expressions by the position SSA reports for them, with the
+	// enclosing nodes needed to build a suggested fix.
+	type callSite struct {
+		call  *ast.CallExpr
+		stack []ast.Node
+	}
+	sites := map[token.Pos]callSite{}
+	insp.WithStack([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if push {
+			call := n.(*ast.CallExpr)
+			sites[call.Lparen] = callSite{call, append([]ast.Node(nil), stack...)}
 		}
+		return true
+	})
 
-		call := n.(*ast.CallExpr)
-		sel, ok := call.Fun.(*ast.SelectorExpr)
-		if !ok || sel.Sel == nil {
-			return true
+	funcs := ssaInfo.SrcFuncs
+	if init := ssaInfo.Pkg.Func("init"); init != nil {
+		funcs = append(funcs[:len(funcs):len(funcs)], init)
+	}
+	tracer := &contextTracer{globals: globalStores(funcs)}
+	callers := staticCallers(ssaInfo.SrcFuncs)
+
+	for _, fn := range ssaInfo.SrcFuncs {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				ci, ok := instr.(ssa.CallInstruction)
+				if !ok {
+					continue
+				}
+				common := ci.Common()
+				arg := clientContextArg(common)
+				if arg == nil || !tracer.fromBackground(arg, 0) {
+					continue
+				}
+
+				d := analysis.Diagnostic{Pos: common.Pos()}
+				site, hasSite := sites[common.Pos()]
+				if hasSite {
+					if id := calleeIdent(site.call.Fun); id != nil {
+						d.Pos = id.Pos()
+					}
+				}
+				if hasContextParam(fn) {
+					d.Message = "client call uses context.Background/TODO; propagate a request context instead"
+					if hasSite {
+						d.SuggestedFixes = useContextParamFix(pass, site.call, site.stack)
+					}
+				} else if caller := callerWithContext(fn, callers); caller != nil {
+					d.Message = "client call uses context.Background/TODO although caller " + caller.Name() +
+						" has a context; accept and propagate a ctx parameter"
+				} else {
+					continue
+				}
+				pass.Report(d)
+			}
 		}
+	}
+	return nil, nil
+}
+
+// clientContextArg returns the argument passed as the context.Context
+// parameter of a client-go or controller-runtime call, or nil.
+func clientContextArg(common *ssa.CallCommon) ssa.Value {
+	callee := ssaCallee(common)
+	if callee == nil || callee.Pkg() == nil || !isKubernetesClientPackage(callee.Pkg().Path()) {
+		return nil
+	}
+	sig := common.Signature()
+	// Args include the receiver for static method calls.
+	offset := 0
+	if !common.IsInvoke() && sig.Recv() != nil {
+		offset = 1
+	}
+	params := sig.Params()
+	for i := 0; i < params.Len(); i++ {
+		if isContextType(params.At(i).Type()) && i+offset < len(common.Args) {
+			return common.Args[i+offset]
+		}
+	}
+	return nil
+}
+
+func isContextType(t types.Type) bool {
+	return isNamed(t, "context", "Context")
+}
+
+// maxTraceDepth bounds how far contextTracer follows values.
+const maxTraceDepth = 16
 
-		if len(call.Args) == 0 {
+// contextTracer decides whether a value is a context derived from
+// context.Background or context.TODO.
+type contextTracer struct {
+	globals map[*ssa.Global][]ssa.Value
+}
+
+func (t *contextTracer) fromBackground(v ssa.Value, depth int) bool {
+	if depth > maxTraceDepth {
+		return false
+	}
+	depth++
+	switch v := v.(type) {
+	case *ssa.Call:
+		callee := v.Call.StaticCallee()
+		if callee == nil || callee.Pkg == nil || callee.Pkg.Pkg.Path() != "context" {
+			return false
+		}
+		switch callee.Name() {
+		case "Background", "TODO":
 			return true
+		case "WithValue", "WithoutCancel":
+			return len(v.Call.Args) > 0 && t.fromBackground(v.Call.Args[0], depth)
+		}
+	case *ssa.Extract:
+		// ctx, cancel := context.WithTimeout(parent, ...)
+		call, ok := v.Tuple.(*ssa.Call)
+		if !ok || v.Index != 0 {
+			return false
+		}
+		callee := call.Call.StaticCallee()
+		if callee == nil || callee.Pkg == nil || callee.Pkg.Pkg.Path() != "context" {
+			return false
+		}
+		return len(call.Call.Args) > 0 && t.fromBackground(call.Call.Args[0], depth)
+	case *ssa.Phi:
+		// Every path must lead to Background, so that
+		// "if ctx == nil { ctx = context.Background() }" is left alone.
+		for _, e := range v.Edges {
+			if !t.fromBackground(e, depth) {
+				return false
+			}
 		}
+		return len(v.Edges) > 0
+	case *ssa.ChangeInterface:
+		return t.fromBackground(v.X, depth)
+	case *ssa.MakeInterface:
+		return t.fromBackground(v.X, depth)
+	case *ssa.ChangeType:
+		return t.fromBackground(v.X, depth)
+	case *ssa.UnOp:
+		if v.Op != token.MUL {
+			return false
+		}
+		return t.storedFromBackground(v.X, depth)
+	case *ssa.FreeVar:
+		return t.fromBackground(boundValue(v), depth)
+	}
+	return false
+}
 
-		// Check if this is a Kubernetes client method using type information
-		if obj := pass.TypesInfo.Uses[sel.Sel]; isKubernetesMethodCall(obj, "Get", "List", "Create", "Update", "Patch", "Delete", "Watch") {
-			if isContextBackgroundOrTODO(call.Args[0]) {
-				pass.Reportf(sel.Sel.Pos(), "client call uses context.Background/TODO; propagate a request context instead")
+// storedFromBackground reports whether every value stored at addr, a local
+// or package-level variable, is derived from Background.
+func (t *contextTracer) storedFromBackground(addr ssa.Value, depth int) bool {
+	var stored []ssa.Value
+	switch addr := addr.(type) {
+	case *ssa.Global:
+		stored = t.globals[addr]
+	case *ssa.Alloc:
+		for _, ref := range *addr.Referrers() {
+			if st, ok := ref.(*ssa.Store); ok && st.Addr == addr {
+				stored = append(stored, st.Val)
 			}
 		}
+	case *ssa.FreeVar:
+		return t.storedFromBackground(boundValue(addr), depth)
+	default:
+		return false
+	}
+	for _, v := range stored {
+		if !t.fromBackground(v, depth) {
+			return false
+		}
+	}
+	return len(stored) > 0
+}
 
-		return true
-	})
+// boundValue returns the value bound to a free variable by the closure that
+// creates its function, or nil when there is not exactly one such closure.
+func boundValue(fv *ssa.FreeVar) ssa.Value {
+	fn := fv.Parent()
+	idx := -1
+	for i, v := range fn.FreeVars {
+		if v == fv {
+			idx = i
+		}
+	}
+	var bound ssa.Value
+	if parent := fn.Parent(); parent != nil && idx >= 0 {
+		for _, b := range parent.Blocks {
+			for _, instr := range b.Instrs {
+				if mc, ok := instr.(*ssa.MakeClosure); ok && mc.Fn == fn {
+					if bound != nil {
+						return nil
+					}
+					bound = mc.Bindings[idx]
+				}
+			}
+		}
+	}
+	return bound
+}
 
-	return nil, nil
+// globalStores collects the values stored to package-level variables by
+// funcs, which include the package initializer.
+func globalStores(funcs []*ssa.Function) map[*ssa.Global][]ssa.Value {
+	out := map[*ssa.Global][]ssa.Value{}
+	for _, fn := range funcs {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				if st, ok := instr.(*ssa.Store); ok {
+					if g, ok := st.Addr.(*ssa.Global); ok {
+						out[g] = append(out[g], st.Val)
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+// hasContextParam reports whether fn, or a function it is nested in, has a
+// usable context.Context parameter.
+func hasContextParam(fn *ssa.Function) bool {
+	for ; fn != nil; fn = fn.Parent() {
+		for _, p := range fn.Params {
+			if isContextType(p.Type()) && p.Name() != "_" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// staticCallers maps each function to the functions that call it directly.
+func staticCallers(funcs []*ssa.Function) map[*ssa.Function][]*ssa.Function {
+	out := map[*ssa.Function][]*ssa.Function{}
+	for _, fn := range funcs {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				if ci, ok := instr.(ssa.CallInstruction); ok {
+					if callee := ci.Common().StaticCallee(); callee != nil {
+						out[callee] = append(out[callee], fn)
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+// callerWithContext returns a caller of fn in the package that has a context
+// parameter, or nil.
+func callerWithContext(fn *ssa.Function, callers map[*ssa.Function][]*ssa.Function) *ssa.Function {
+	for _, c := range callers[fn] {
+		if hasContextParam(c) {
+			return c
+		}
+	}
+	return nil
+}
+
+// useContextParamFix replaces a literal context.Background() or
+// context.TODO() argument of call with the context parameter of the
+// enclosing function.
+func useContextParamFix(pass *analysis.Pass, call *ast.CallExpr, stack []ast.Node) []analysis.SuggestedFix {
+	ctx := enclosingContextParam(pass, stack)
+	if ctx == "" {
+		return nil
+	}
+	for _, arg := range call.Args {
+		if !isContextBackgroundOrTODO(arg) {
+			continue
+		}
+		return []analysis.SuggestedFix{{
+			Message:   "Use " + ctx + " from the enclosing function",
+			TextEdits: []analysis.TextEdit{{Pos: arg.Pos(), End: arg.End(), NewText: []byte(ctx)}},
+		}}
+	}
+	return nil
 }
 
 func isContextBackgroundOrTODO(arg ast.Expr) bool {
@@ -58,3 +321,30 @@ func isContextBackgroundOrTODO(arg ast.Expr) bool {
 	}
 	return false
 }
+
+// enclosingContextParam returns the name of the context.Context parameter of
+// the innermost enclosing function that has one, or "".
+func enclosingContextParam(pass *analysis.Pass, stack []ast.Node) string {
+	for i := len(stack) - 1; i >= 0; i-- {
+		var ft *ast.FuncType
+		switch fn := stack[i].(type) {
+		case *ast.FuncDecl:
+			ft = fn.Type
+		case *ast.FuncLit:
+			ft = fn.Type
+		default:
+			continue
+		}
+		for _, field := range ft.Params.List {
+			if !isContextType(pass.TypesInfo.TypeOf(field.Type)) {
+				continue
+			}
+			for _, name := range field.Names {
+				if name.Name != "_" {
+					return name.Name
+				}
+			}
+		}
+	}
+	return ""
+}
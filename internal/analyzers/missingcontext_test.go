@@ -1,6 +1,7 @@
 package analyzers
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/amisstea/k8s-client-audit/internal/analyzers/testutil"
@@ -8,73 +9,186 @@ import (
 	"golang.org/x/tools/go/analysis"
 )
 
-func runMissingCtxAnalyzerOnSrc(t *testing.T, src string, spoof bool) []analysis.Diagnostic {
+func runMissingCtxAnalyzerOnSrc(t *testing.T, src string) []analysis.Diagnostic {
 	t.Helper()
-	var diags []analysis.Diagnostic
-	var err error
-	if spoof {
-		diags, err = testutil.RunAnalyzerOnSrc(AnalyzerMissingContext, src, testutil.SpoofCommonK8s)
-	} else {
-		diags, err = testutil.RunAnalyzerOnSrc(AnalyzerMissingContext, src)
-	}
+	res, err := testutil.RunAnalyzerOnStubbedSrc(AnalyzerMissingContext, src)
 	if err != nil {
 		t.Fatalf("run: %v", err)
 	}
-	return diags
+	return res.Diagnostics
 }
 
+const missingCtxImports = `package p
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	_ = time.Second
+	_ corev1.Pod
+	_ metav1.GetOptions
+	_ types.NamespacedName
+	_ kubernetes.Interface
+	_ client.Client
+)
+`
+
 func TestMissingContext_BackgroundFlagged(t *testing.T) {
-	src := `package a
-import "context"
-type C interface{ Get(ctx any) }
-func f(c C){ c.Get(context.Background()) }`
-	diags := runMissingCtxAnalyzerOnSrc(t, src, true) // spoof as Kubernetes types
-	if len(diags) == 0 {
-		t.Fatalf("expected diagnostic for Background")
+	src := missingCtxImports + `
+func f(ctx context.Context, cs kubernetes.Interface) {
+	_, _ = cs.CoreV1().Pods("ns").Get(context.Background(), "a", metav1.GetOptions{})
+}`
+	if diags := runMissingCtxAnalyzerOnSrc(t, src); len(diags) != 1 {
+		t.Fatalf("expected 1 diagnostic for Background, got %d", len(diags))
 	}
 }
 
 func TestMissingContext_Propagated_NoDiag(t *testing.T) {
-	src := `package a
-type C interface{ Get(ctx any) }
-func f(c C, ctx any){ c.Get(ctx) }`
-	diags := runMissingCtxAnalyzerOnSrc(t, src, true) // spoof as Kubernetes types
-	if len(diags) != 0 {
+	src := missingCtxImports + `
+func f(ctx context.Context, cs kubernetes.Interface) {
+	_, _ = cs.CoreV1().Pods("ns").Get(ctx, "a", metav1.GetOptions{})
+	tctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	_, _ = cs.CoreV1().Pods("ns").Get(tctx, "a", metav1.GetOptions{})
+}`
+	if diags := runMissingCtxAnalyzerOnSrc(t, src); len(diags) != 0 {
 		t.Fatalf("expected 0 diagnostics, got %d", len(diags))
 	}
 }
 
 func TestMissingContext_GitHubClient_NoDiag(t *testing.T) {
-	src := `package a
-import "context"
+	src := missingCtxImports + `
 type GitHubAppsService struct{}
-func (g *GitHubAppsService) Get(ctx context.Context, slug string) (interface{}, interface{}, error) { return nil, nil, nil }
+
+func (g *GitHubAppsService) Get(ctx context.Context, slug string) (interface{}, interface{}, error) {
+	return nil, nil, nil
+}
+
 type GitHubClient struct{ Apps *GitHubAppsService }
-func f(client *GitHubClient){ client.Apps.Get(context.Background(), "") }`
-	diags := runMissingCtxAnalyzerOnSrc(t, src, false) // don't spoof - should be treated as non-Kubernetes
-	if len(diags) != 0 {
+
+func f(ctx context.Context, client *GitHubClient) { _, _, _ = client.Apps.Get(context.Background(), "") }`
+	if diags := runMissingCtxAnalyzerOnSrc(t, src); len(diags) != 0 {
 		t.Fatalf("expected 0 diagnostics for GitHub client calls, got %d", len(diags))
 	}
 }
 
 func TestMissingContext_GenericClient_NoDiag(t *testing.T) {
-	src := `package a
-import "context"
+	src := missingCtxImports + `
 type HTTPClient interface{ Get(ctx context.Context, url string) error }
-func f(client HTTPClient){ client.Get(context.Background(), "https://api.github.com") }`
-	diags := runMissingCtxAnalyzerOnSrc(t, src, false) // don't spoof - should be treated as non-Kubernetes
-	if len(diags) != 0 {
+
+func f(ctx context.Context, client HTTPClient) { _ = client.Get(context.Background(), "https://api.github.com") }`
+	if diags := runMissingCtxAnalyzerOnSrc(t, src); len(diags) != 0 {
 		t.Fatalf("expected 0 diagnostics for non-Kubernetes client calls, got %d", len(diags))
 	}
 }
 
-func TestMissingContext_KubernetesClient_Flagged(t *testing.T) {
-	src := `package a
-import "context"
-type KubernetesClient interface{ Get(ctx context.Context, name string) error }
-func f(client KubernetesClient){ client.Get(context.Background(), "my-pod") }`
-	diags := runMissingCtxAnalyzerOnSrc(t, src, true) // spoof as Kubernetes types
-	if len(diags) == 0 {
-		t.Fatalf("expected diagnostic for Kubernetes client with Background context")
+func TestMissingContext_TracesLocalsGlobalsAndWrappers(t *testing.T) {
+	src := missingCtxImports + `
+var bg = context.Background()
+
+func local(ctx context.Context, c client.Client) error {
+	todo := context.TODO()
+	return c.Get(todo, types.NamespacedName{}, &corev1.Pod{})
+}
+
+func global(ctx context.Context, c client.Client) error {
+	return c.Get(bg, types.NamespacedName{}, &corev1.Pod{})
+}
+
+func wrapped(ctx context.Context, cs kubernetes.Interface) error {
+	tctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err := cs.CoreV1().Pods("ns").List(tctx, metav1.ListOptions{})
+	return err
+}
+
+func closure(ctx context.Context, c client.Client) {
+	bctx := context.Background()
+	go func() { _ = c.Get(bctx, types.NamespacedName{}, &corev1.Pod{}) }()
+}`
+	diags := runMissingCtxAnalyzerOnSrc(t, src)
+	if len(diags) != 4 {
+		for _, d := range diags {
+			t.Log(d.Message)
+		}
+		t.Fatalf("expected 4 diagnostics, got %d", len(diags))
+	}
+}
+
+func TestMissingContext_NoContextAvailable_NoDiag(t *testing.T) {
+	src := missingCtxImports + `
+func main() {
+	var c client.Client
+	_ = c.Get(context.Background(), types.NamespacedName{}, &corev1.Pod{})
+}
+
+func withDefault(ctx context.Context, c client.Client) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return c.Get(ctx, types.NamespacedName{}, &corev1.Pod{})
+}`
+	if diags := runMissingCtxAnalyzerOnSrc(t, src); len(diags) != 0 {
+		t.Fatalf("expected 0 diagnostics, got %d", len(diags))
+	}
+}
+
+func TestMissingContext_CallerHasContext_Flagged(t *testing.T) {
+	src := missingCtxImports + `
+func Reconcile(ctx context.Context, c client.Client) error { return fetch(c) }
+
+func fetch(c client.Client) error {
+	return c.Get(context.TODO(), types.NamespacedName{}, &corev1.Pod{})
+}`
+	diags := runMissingCtxAnalyzerOnSrc(t, src)
+	if len(diags) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %d", len(diags))
+	}
+	if !strings.Contains(diags[0].Message, "caller Reconcile") {
+		t.Fatalf("expected the message to name the caller, got %q", diags[0].Message)
+	}
+	if len(diags[0].SuggestedFixes) != 0 {
+		t.Fatalf("did not expect a fix without a context parameter in scope")
+	}
+}
+
+func TestMissingContext_SuggestedFix_UsesCtxParam(t *testing.T) {
+	src := `package p
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+func get(cs kubernetes.Interface, reqCtx context.Context) (*corev1.Pod, error) {
+	return cs.CoreV1().Pods("ns").Get(context.TODO(), "name", metav1.GetOptions{})
+}
+
+func list(cs kubernetes.Interface) error {
+	_, err := cs.CoreV1().Pods("ns").List(context.Background(), metav1.ListOptions{})
+	return err
+}
+`
+	res, err := testutil.RunAnalyzerOnStubbedSrc(AnalyzerMissingContext, src)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(res.Diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %d", len(res.Diagnostics))
+	}
+	fixed := testutil.MustApplyFixes(t, res)["p"]
+	if !strings.Contains(fixed, `Get(reqCtx, "name"`) {
+		t.Fatalf("expected context.TODO to be replaced by reqCtx:\n%s", fixed)
 	}
 }
@@ -3,7 +3,6 @@ package analyzers
 import (
 	"go/ast"
 	"go/types"
-	"strings"
 
 	"golang.org/x/tools/go/analysis"
 	insppass "golang.org/x/tools/go/analysis/passes/inspect"
@@ -23,29 +22,6 @@ var AnalyzerMissingInformer = &analysis.Analyzer{
 func runMissingInformer(pass *analysis.Pass) (any, error) {
 	insp := pass.ResultOf[insppass.Analyzer].(*inspector.Inspector)
 
-	// Check if a method call is a Kubernetes SharedInformer constructor
-	isKubernetesInformerConstructor := func(obj types.Object) bool {
-		if obj == nil || obj.Pkg() == nil {
-			return false
-		}
-		name := obj.Name()
-		if !(name == "NewSharedInformerFactory" || name == "NewSharedInformerFactoryWithOptions" || name == "NewSharedIndexInformer" || name == "NewSharedInformer") {
-			return false
-		}
-		pkg := obj.Pkg().Path()
-
-		// Check for Kubernetes informer packages
-		switch {
-		case strings.HasPrefix(pkg, "k8s.io/client-go/informers"):
-			return true
-		case strings.HasPrefix(pkg, "k8s.io/client-go/tools/cache"):
-			return true
-		case strings.HasPrefix(pkg, "sigs.k8s.io/controller-runtime/pkg/cache"):
-			return true
-		}
-		return false
-	}
-
 	// Check if a method call is a Kubernetes Watch operation
 	isKubernetesWatchCall := func(obj types.Object) bool {
 		if obj == nil || obj.Pkg() == nil {
@@ -91,7 +67,7 @@ func runMissingInformer(pass *analysis.Pass) (any, error) {
 		}
 
 		if obj != nil {
-			if isKubernetesInformerConstructor(obj) {
+			if isInformerConstructor(obj) {
 				hasInformer = true
 			} else if isKubernetesWatchCall(obj) {
 				watchCalls = append(watchCalls, ce)
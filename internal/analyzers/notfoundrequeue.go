@@ -0,0 +1,132 @@
+package analyzers
+
+import (
+	"go/ast"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+	"golang.org/x/tools/go/ssa"
+)
+
+// AnalyzerNotFoundRequeue flags Reconcile methods that return the error of a
+// client Get without checking for NotFound.
+//
+// controller-runtime requeues a request whose Reconcile returns an error, so
+// returning the NotFound error for an object that was deleted retries it
+// forever. A return of the error, as is or wrapped with fmt.Errorf, is
+// reported unless an apierrors.IsNotFound check of the error executes before
+// it on every path; client.IgnoreNotFound results are new values and are not
+// followed. Reconcile methods and configured hot paths are checked, as for
+// isHotPath.
+var AnalyzerNotFoundRequeue = &analysis.Analyzer{
+	Name:     "notfoundrequeue",
+	Doc:      "flags Reconcile returning the error of Get without apierrors.IsNotFound or client.IgnoreNotFound",
+	Run:      runNotFoundRequeue,
+	Requires: []*analysis.Analyzer{buildssa.Analyzer},
+}
+
+func runNotFoundRequeue(pass *analysis.Pass) (any, error) {
+	ssaInfo := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+
+	for _, fn := range ssaInfo.SrcFuncs {
+		fd, ok := fn.Syntax().(*ast.FuncDecl)
+		if !ok || !isHotPath(pass, fd) {
+			continue
+		}
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				call, ok := instr.(*ssa.Call)
+				if !ok {
+					continue
+				}
+				callee := ssaCallee(&call.Call)
+				if callee == nil || !isKubernetesMethodCall(callee, "Get") {
+					continue
+				}
+				err := callError(call)
+				if err == nil {
+					continue
+				}
+				flows := errorFlows(err)
+				var checks []ssa.Instruction
+				for v := range flows {
+					for _, ref := range *v.Referrers() {
+						if ci, ok := ref.(ssa.CallInstruction); ok && isNotFoundCheck(ci.Common()) {
+							checks = append(checks, ci)
+						}
+					}
+				}
+				for _, rb := range fn.Blocks {
+					ret, ok := rb.Instrs[len(rb.Instrs)-1].(*ssa.Return)
+					if !ok || len(ret.Results) == 0 || anyDominates(checks, ret) {
+						continue
+					}
+					if res := ret.Results[len(ret.Results)-1]; flows[res] || wrapsError(res, flows) {
+						pass.Reportf(ret.Pos(), "error of %s returned without checking apierrors.IsNotFound; a deleted object is requeued forever, use client.IgnoreNotFound", callee.Name())
+					}
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// errorFlows returns err and the values it flows into unchanged: phis and
+// interface conversions.
+func errorFlows(err ssa.Value) map[ssa.Value]bool {
+	flows := map[ssa.Value]bool{}
+	stack := []ssa.Value{err}
+	for len(stack) > 0 {
+		v := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if flows[v] {
+			continue
+		}
+		flows[v] = true
+		for _, ref := range *v.Referrers() {
+			switch ref := ref.(type) {
+			case *ssa.Phi:
+				stack = append(stack, ref)
+			case *ssa.ChangeInterface:
+				stack = append(stack, ref)
+			}
+		}
+	}
+	return flows
+}
+
+// isNotFoundCheck reports whether common is a call of apierrors.IsNotFound.
+func isNotFoundCheck(common *ssa.CallCommon) bool {
+	fn := ssaCallee(common)
+	return fn != nil && fn.Pkg() != nil && fn.Pkg().Path() == PkgAPIErrors && fn.Name() == "IsNotFound"
+}
+
+// wrapsError reports whether v is the result of fmt.Errorf, or of a
+// github.com/pkg/errors wrapper, with one of errs as an argument.
+func wrapsError(v ssa.Value, errs map[ssa.Value]bool) bool {
+	call, ok := v.(*ssa.Call)
+	if !ok {
+		return false
+	}
+	fn := ssaCallee(&call.Call)
+	if fn == nil || fn.Pkg() == nil {
+		return false
+	}
+	switch fn.Pkg().Path() + "." + fn.Name() {
+	case "fmt.Errorf", "github.com/pkg/errors.Wrap", "github.com/pkg/errors.Wrapf",
+		"github.com/pkg/errors.WithStack", "github.com/pkg/errors.WithMessage":
+	default:
+		return false
+	}
+	for _, arg := range call.Call.Args {
+		if errs[objectRoot(arg)] {
+			return true
+		}
+		for _, v := range variadicValues(arg) {
+			if errs[objectRoot(v)] {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package analyzers
+
+import (
+	"testing"
+
+	"github.com/amisstea/k8s-client-audit/internal/analyzers/testutil"
+)
+
+const notFoundRequeueImports = `package p
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var (
+	_ = context.TODO
+	_ = fmt.Errorf
+	_ corev1.Pod
+	_ = apierrors.IsNotFound
+	_ client.Client
+	_ reconcile.Result
+)
+
+type Reconciler struct{ c client.Client }
+`
+
+func runNotFoundRequeueOnSrc(t *testing.T, src string) int {
+	t.Helper()
+	res, err := testutil.RunAnalyzerOnStubbedSrc(AnalyzerNotFoundRequeue, src)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	return len(res.Diagnostics)
+}
+
+func TestNotFoundRequeue_ReturnedError_Flagged(t *testing.T) {
+	src := notFoundRequeueImports + `
+func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	var pod corev1.Pod
+	if err := r.c.Get(ctx, req.NamespacedName, &pod); err != nil {
+		return reconcile.Result{}, err
+	}
+	var cm corev1.ConfigMap
+	if err := r.c.Get(ctx, req.NamespacedName, &cm); err != nil {
+		return reconcile.Result{}, fmt.Errorf("get config map %s: %w", req.Name, err)
+	}
+	return reconcile.Result{}, nil
+}`
+	if n := runNotFoundRequeueOnSrc(t, src); n != 2 {
+		t.Fatalf("expected 2 diagnostics, got %d", n)
+	}
+}
+
+func TestNotFoundRequeue_Handled_NoDiag(t *testing.T) {
+	src := notFoundRequeueImports + `
+func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	var pod corev1.Pod
+	if err := r.c.Get(ctx, req.NamespacedName, &pod); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	var cm corev1.ConfigMap
+	if err := r.c.Get(ctx, req.NamespacedName, &cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, nil
+}
+
+func (r *Reconciler) get(ctx context.Context, key client.ObjectKey) error {
+	var pod corev1.Pod
+	return r.c.Get(ctx, key, &pod)
+}`
+	if n := runNotFoundRequeueOnSrc(t, src); n != 0 {
+		t.Fatalf("expected 0 diagnostics, got %d", n)
+	}
+}
+
+func TestNotFoundRequeue_ConfiguredHotPath_Flagged(t *testing.T) {
+	testutil.SetAnalyzerFlag(t, AnalyzerNotFoundRequeue, "hot-paths", "Reconciler.sync")
+	src := notFoundRequeueImports + `
+func (r *Reconciler) sync(ctx context.Context, key client.ObjectKey) error {
+	var pod corev1.Pod
+	if err := r.c.Get(ctx, key, &pod); err != nil {
+		return err
+	}
+	return nil
+}`
+	if n := runNotFoundRequeueOnSrc(t, src); n != 1 {
+		t.Fatalf("expected 1 diagnostic, got %d", n)
+	}
+}
@@ -0,0 +1,207 @@
+package analyzers
+
+import (
+	"go/token"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+	"golang.org/x/tools/go/ssa"
+)
+
+// AnalyzerPreferPatch flags Update calls that write back an object read with
+// Get in the same function, and Updates of the main resource where only the
+// Status of the object was modified.
+//
+// Update sends the whole object with the resourceVersion it was read at, so a
+// Get-modify-Update fails with a conflict whenever another writer changed the
+// object in between; a Patch only sends the modified fields. Status is served
+// by its own subresource, so Status changes sent with the main resource are
+// dropped. Both the controller-runtime client (including Status()) and the
+// typed client-go clients are covered. Modifications are field writes, map
+// updates and calls that take the object or one of its fields, followed
+// through nested fields of the object.
+var AnalyzerPreferPatch = &analysis.Analyzer{
+	Name:     "preferpatch",
+	Doc:      "flags Get-modify-Update of the same object and status-only changes sent with Update of the main resource; prefer Patch",
+	Run:      runPreferPatch,
+	Requires: []*analysis.Analyzer{buildssa.Analyzer},
+}
+
+func runPreferPatch(pass *analysis.Pass) (any, error) {
+	ssaInfo := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+
+	for _, fn := range ssaInfo.SrcFuncs {
+		var gets, updates []ssa.CallInstruction
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				ci, ok := instr.(ssa.CallInstruction)
+				if !ok {
+					continue
+				}
+				callee := ssaCallee(ci.Common())
+				switch {
+				case callee == nil:
+				case isKubernetesMethodCall(callee, "Get"):
+					gets = append(gets, ci)
+				case isKubernetesMethodCall(callee, "Update", "UpdateStatus"):
+					updates = append(updates, ci)
+				}
+			}
+		}
+		for _, u := range updates {
+			common := u.Common()
+			args := ssaArgs(common)
+			if len(args) < 2 {
+				continue
+			}
+			obj := objectRoot(args[1])
+			mods := objectModifications(obj)
+			if len(mods) == 0 {
+				continue
+			}
+			callee := ssaCallee(common)
+			runtime := callee.Pkg().Path() == PkgControllerRuntimeClient
+			status := callee.Name() == "UpdateStatus" || isStatusWriter(ssaReceiver(common))
+
+			switch {
+			case !status && len(mods) == 1 && mods["Status"]:
+				if runtime {
+					pass.Reportf(u.Pos(), "Update of the main resource after modifying only Status; the API server ignores status changes there, use Status().Patch or Status().Update")
+				} else {
+					pass.Reportf(u.Pos(), "Update of the main resource after modifying only Status; the API server ignores status changes there, use UpdateStatus or Patch the status subresource")
+				}
+			case !fetchedBefore(gets, obj, u):
+			case runtime && status:
+				pass.Reportf(u.Pos(), "object read with Get is modified and written back with Status().Update, which conflicts with concurrent writers; use Status().Patch with client.MergeFrom")
+			case runtime:
+				pass.Reportf(u.Pos(), "object read with Get is modified and written back with Update, which conflicts with concurrent writers; use Patch with client.MergeFrom or server-side apply")
+			default:
+				pass.Reportf(u.Pos(), "object read with Get is modified and written back with %s, which conflicts with concurrent writers; use Patch with a merge patch or server-side apply", callee.Name())
+			}
+		}
+	}
+	return nil, nil
+}
+
+// objectRoot returns the object v refers to, looking through conversions to
+// interfaces like client.Object.
+func objectRoot(v ssa.Value) ssa.Value {
+	for {
+		switch x := v.(type) {
+		case *ssa.MakeInterface:
+			v = x.X
+		case *ssa.ChangeInterface:
+			v = x.X
+		case *ssa.ChangeType:
+			v = x.X
+		default:
+			return v
+		}
+	}
+}
+
+// objectModifications returns the names of the top-level fields of the
+// object obj points to that are modified, with "" standing for changes made
+// to the object as a whole.
+func objectModifications(obj ssa.Value) map[string]bool {
+	mods := map[string]bool{}
+	seen := map[ssa.Value]bool{}
+	var walk func(v ssa.Value, field string)
+	walk = func(v ssa.Value, field string) {
+		if seen[v] || v.Referrers() == nil {
+			return
+		}
+		seen[v] = true
+		for _, ref := range *v.Referrers() {
+			switch ref := ref.(type) {
+			case *ssa.FieldAddr:
+				name := field
+				if name == "" {
+					if f := structField(deref(v.Type()), ref.Field); f != nil {
+						name = f.Name()
+					}
+				}
+				walk(ref, name)
+			case *ssa.IndexAddr:
+				walk(ref, field)
+			case *ssa.UnOp:
+				if ref.Op == token.MUL && isReferenceType(ref.Type()) {
+					walk(ref, field)
+				}
+			case *ssa.MakeInterface:
+				walk(ref, field)
+			case *ssa.ChangeInterface:
+				walk(ref, field)
+			case *ssa.ChangeType:
+				walk(ref, field)
+			case *ssa.Store:
+				if ref.Addr == v {
+					mods[field] = true
+				}
+			case *ssa.MapUpdate:
+				if ref.Map == v {
+					mods[field] = true
+				}
+			case ssa.CallInstruction:
+				if modifiesArg(ref.Common(), v) {
+					mods[field] = true
+				}
+			}
+		}
+	}
+	walk(obj, "")
+	return mods
+}
+
+// modifiesArg reports whether a call taking v may modify what v refers to.
+// Kubernetes client calls and methods of the object other than setters do
+// not; other functions, like controllerutil.AddFinalizer or
+// meta.SetStatusCondition, are assumed to.
+func modifiesArg(common *ssa.CallCommon, v ssa.Value) bool {
+	if _, ok := common.Value.(*ssa.Builtin); ok {
+		return false
+	}
+	callee := ssaCallee(common)
+	if callee == nil {
+		return true
+	}
+	if callee.Pkg() != nil && isKubernetesClientPackage(callee.Pkg().Path()) {
+		return false
+	}
+	if ssaReceiver(common) == v {
+		return strings.HasPrefix(callee.Name(), "Set")
+	}
+	return true
+}
+
+// isStatusWriter reports whether recv is the result of the Status() or
+// SubResource() method of a controller-runtime client.
+func isStatusWriter(recv ssa.Value) bool {
+	call, ok := recv.(*ssa.Call)
+	if !ok {
+		return false
+	}
+	callee := ssaCallee(&call.Call)
+	return callee != nil && callee.Pkg() != nil && callee.Pkg().Path() == PkgControllerRuntimeClient &&
+		(callee.Name() == "Status" || callee.Name() == "SubResource")
+}
+
+// fetchedBefore reports whether one of gets reads obj and executes before
+// update on every path to it: the object argument of a controller-runtime
+// Get, or the object returned by a typed client Get.
+func fetchedBefore(gets []ssa.CallInstruction, obj ssa.Value, update ssa.Instruction) bool {
+	for _, g := range gets {
+		fetched := false
+		if ssaCallee(g.Common()).Pkg().Path() == PkgControllerRuntimeClient {
+			args := ssaArgs(g.Common())
+			fetched = len(args) >= 3 && objectRoot(args[2]) == obj
+		} else if ex, ok := obj.(*ssa.Extract); ok {
+			fetched = ex.Index == 0 && ex.Tuple == g.Value()
+		}
+		if fetched && dominates(g, update) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package analyzers
+
+import (
+	"testing"
+
+	"github.com/amisstea/k8s-client-audit/internal/analyzers/testutil"
+)
+
+const preferPatchImports = `package p
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	_ = context.TODO
+	_ corev1.Pod
+	_ metav1.UpdateOptions
+	_ kubernetes.Interface
+	_ client.Client
+)
+`
+
+func runPreferPatchOnSrc(t *testing.T, src string) int {
+	t.Helper()
+	res, err := testutil.RunAnalyzerOnStubbedSrc(AnalyzerPreferPatch, src)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	return len(res.Diagnostics)
+}
+
+func TestPreferPatch_GetModifyUpdate_Flagged(t *testing.T) {
+	src := preferPatchImports + `
+func label(ctx context.Context, c client.Client, key client.ObjectKey) error {
+	var pod corev1.Pod
+	if err := c.Get(ctx, key, &pod); err != nil {
+		return err
+	}
+	pod.Labels["synced"] = "true"
+	return c.Update(ctx, &pod)
+}
+
+func phase(ctx context.Context, c client.Client, key client.ObjectKey) error {
+	pod := &corev1.Pod{}
+	if err := c.Get(ctx, key, pod); err != nil {
+		return err
+	}
+	pod.Status.Phase = "Running"
+	return c.Status().Update(ctx, pod)
+}
+
+func typed(ctx context.Context, cs kubernetes.Interface) error {
+	pod, err := cs.CoreV1().Pods("ns").Get(ctx, "a", metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	pod.Spec.NodeName = "node"
+	_, err = cs.CoreV1().Pods("ns").Update(ctx, pod, metav1.UpdateOptions{})
+	return err
+}`
+	if n := runPreferPatchOnSrc(t, src); n != 3 {
+		t.Fatalf("expected 3 diagnostics, got %d", n)
+	}
+}
+
+func TestPreferPatch_StatusOnlyUpdate_Flagged(t *testing.T) {
+	src := preferPatchImports + `
+func markRunning(ctx context.Context, c client.Client, pod *corev1.Pod) error {
+	pod.Status.Phase = "Running"
+	return c.Update(ctx, pod)
+}
+
+func typed(ctx context.Context, cs kubernetes.Interface) error {
+	pod, err := cs.CoreV1().Pods("ns").Get(ctx, "a", metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	pod.Status.Phase = "Running"
+	_, err = cs.CoreV1().Pods("ns").Update(ctx, pod, metav1.UpdateOptions{})
+	return err
+}`
+	if n := runPreferPatchOnSrc(t, src); n != 2 {
+		t.Fatalf("expected 2 diagnostics, got %d", n)
+	}
+}
+
+func TestPreferPatch_PatchAndFreshObjects_NoDiag(t *testing.T) {
+	src := preferPatchImports + `
+func label(ctx context.Context, c client.Client, key client.ObjectKey) error {
+	var pod corev1.Pod
+	if err := c.Get(ctx, key, &pod); err != nil {
+		return err
+	}
+	orig := pod.DeepCopy()
+	pod.Labels["synced"] = "true"
+	return c.Patch(ctx, &pod, client.MergeFrom(orig))
+}
+
+func replace(ctx context.Context, c client.Client, pod *corev1.Pod) error {
+	pod.Spec.NodeName = "node"
+	pod.Status.Phase = "Pending"
+	return c.Update(ctx, pod)
+}
+
+func typedStatus(ctx context.Context, cs kubernetes.Interface, pod *corev1.Pod) error {
+	pod.Status.Phase = "Running"
+	_, err := cs.CoreV1().Pods("ns").UpdateStatus(ctx, pod, metav1.UpdateOptions{})
+	return err
+}`
+	if n := runPreferPatchOnSrc(t, src); n != 0 {
+		t.Fatalf("expected 0 diagnostics, got %d", n)
+	}
+}
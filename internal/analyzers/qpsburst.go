@@ -2,25 +2,41 @@ package analyzers
 
 import (
 	"go/ast"
-	"go/token"
+	"go/constant"
 	"go/types"
-	"strconv"
 
 	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
 	insppass "golang.org/x/tools/go/analysis/passes/inspect"
 	"golang.org/x/tools/go/ast/inspector"
 )
 
 // AnalyzerQPSBurst flags rest.Config.QPS/Burst that are zero/unlimited or extreme.
+// Values are resolved through named constants, variables, struct fields, flag
+// defaults and helper functions returning constants. Configs reaching a client
+// constructor with QPS or Burst never set are reported at the constructor, as
+// for restconfigdefaults.
 var AnalyzerQPSBurst = &analysis.Analyzer{
 	Name:     "qpsburst",
 	Doc:      "flags rest.Config QPS/Burst zero or extreme values",
 	Run:      runQPSBurst,
-	Requires: []*analysis.Analyzer{insppass.Analyzer},
+	Requires: []*analysis.Analyzer{insppass.Analyzer, buildssa.Analyzer},
+}
+
+// Thresholds above which QPS/Burst values are considered extreme.
+var (
+	qpsBurstMaxQPS   = 10000.0
+	qpsBurstMaxBurst = int64(100000)
+)
+
+func init() {
+	AnalyzerQPSBurst.Flags.Float64Var(&qpsBurstMaxQPS, "max-qps", qpsBurstMaxQPS, "QPS values above this are reported as extreme")
+	AnalyzerQPSBurst.Flags.Int64Var(&qpsBurstMaxBurst, "max-burst", qpsBurstMaxBurst, "Burst values above this are reported as extreme")
 }
 
 func runQPSBurst(pass *analysis.Pass) (any, error) {
 	insp := pass.ResultOf[insppass.Analyzer].(*inspector.Inspector)
+	consts := newConstTracer(pass)
 
 	nodes := []ast.Node{(*ast.CompositeLit)(nil), (*ast.AssignStmt)(nil)}
 	insp.Nodes(nodes, func(n ast.Node, push bool) bool {
@@ -30,26 +46,24 @@ func runQPSBurst(pass *analysis.Pass) (any, error) {
 
 		switch n := n.(type) {
 		case *ast.CompositeLit:
-			// Check if this is a Kubernetes rest.Config composite literal
+			// Check if this is a Kubernetes rest.Config composite literal.
+			// Missing fields are reported where the config reaches a client.
 			if isRestConfig(pass.TypesInfo.TypeOf(n)) {
-				hasQPS, hasBurst := false, false
 				badQPS, badBurst := false, false
 				for _, el := range n.Elts {
 					if kv, ok := el.(*ast.KeyValueExpr); ok {
 						if id, ok := kv.Key.(*ast.Ident); ok {
 							switch id.Name {
 							case "QPS":
-								hasQPS = true
-								badQPS = isBadFloat(kv.Value)
+								badQPS = isBadFloat(consts.exprValue(kv.Value, kv.Colon))
 							case "Burst":
-								hasBurst = true
-								badBurst = isBadInt(kv.Value)
+								badBurst = isBadInt(consts.exprValue(kv.Value, kv.Colon))
 							}
 						}
 					}
 				}
-				if (!hasQPS || !hasBurst) || badQPS || badBurst {
-					pass.Reportf(n.Lbrace, "Kubernetes rest.Config QPS/Burst missing or unrealistic")
+				if badQPS || badBurst {
+					pass.Reportf(n.Lbrace, "Kubernetes rest.Config QPS/Burst set to zero or extreme")
 				}
 			}
 		case *ast.AssignStmt:
@@ -69,10 +83,10 @@ func runQPSBurst(pass *analysis.Pass) (any, error) {
 				if !isRestConfig(pass.TypesInfo.TypeOf(sel.X)) {
 					continue
 				}
-				if name == "QPS" && isBadFloat(n.Rhs[i]) {
+				if name == "QPS" && isBadFloat(consts.exprValue(n.Rhs[i], sel.Sel.Pos())) {
 					pass.Reportf(sel.Sel.Pos(), "Kubernetes rest.Config.QPS set to zero or extreme")
 				}
-				if name == "Burst" && isBadInt(n.Rhs[i]) {
+				if name == "Burst" && isBadInt(consts.exprValue(n.Rhs[i], sel.Sel.Pos())) {
 					pass.Reportf(sel.Sel.Pos(), "Kubernetes rest.Config.Burst set to zero or extreme")
 				}
 			}
@@ -81,6 +95,8 @@ func runQPSBurst(pass *analysis.Pass) (any, error) {
 		return true
 	})
 
+	flows := restConfigFlows(pass, consts)
+	reportUnsetRestConfigFields(pass, flows, "client-go then allows 5 QPS with a burst of 10, set limits sized for the workload", "QPS", "Burst")
 	return nil, nil
 }
 
@@ -114,29 +130,18 @@ func isRestConfig(t types.Type) bool {
 	return false
 }
 
-func isBadFloat(e ast.Expr) bool {
-	bl, ok := e.(*ast.BasicLit)
-	if !ok {
+func isBadFloat(v constant.Value) bool {
+	if v == nil || v.Kind() != constant.Int && v.Kind() != constant.Float {
 		return false
 	}
-	if bl.Kind != token.FLOAT && bl.Kind != token.INT {
-		return false
-	}
-	f, err := strconv.ParseFloat(bl.Value, 64)
-	if err != nil {
-		return false
-	}
-	return f == 0 || f > 10000
+	f, _ := constant.Float64Val(v)
+	return f == 0 || f > qpsBurstMaxQPS
 }
 
-func isBadInt(e ast.Expr) bool {
-	bl, ok := e.(*ast.BasicLit)
-	if !ok || bl.Kind != token.INT {
-		return false
-	}
-	i, err := strconv.ParseInt(bl.Value, 10, 64)
-	if err != nil {
+func isBadInt(v constant.Value) bool {
+	if v == nil || v.Kind() != constant.Int {
 		return false
 	}
-	return i == 0 || i > 100000
+	i, exact := constant.Int64Val(v)
+	return !exact || i == 0 || i > qpsBurstMaxBurst
 }
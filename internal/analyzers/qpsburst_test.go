@@ -10,11 +10,11 @@ import (
 
 func runAnalyzerOnSrc(t *testing.T, src string) []analysis.Diagnostic {
 	t.Helper()
-	diags, err := testutil.RunAnalyzerOnSrc(AnalyzerQPSBurst, src)
+	res, err := testutil.RunAnalyzerOnStubbedSrc(AnalyzerQPSBurst, src)
 	if err != nil {
 		t.Fatalf("run: %v", err)
 	}
-	return diags
+	return res.Diagnostics
 }
 
 func TestAnalyzer_ConfigLiteralMissingOrBad(t *testing.T) {
@@ -52,3 +52,107 @@ func f(){ var cfg Config; cfg.QPS = 30; cfg.Burst = 100 }
 		t.Fatalf("expected 0 diagnostics, got %d", len(diags))
 	}
 }
+
+func TestAnalyzer_CustomThresholds(t *testing.T) {
+	testutil.SetAnalyzerFlag(t, AnalyzerQPSBurst, "max-qps", "50")
+	testutil.SetAnalyzerFlag(t, AnalyzerQPSBurst, "max-burst", "100")
+	src := `package a
+type Config struct{ QPS float32; Burst int }
+func f(){ var cfg Config; cfg.QPS = 100; cfg.Burst = 500 }
+`
+	diags := runAnalyzerOnSrc(t, src)
+	if len(diags) != 2 {
+		t.Fatalf("expected 2 diagnostics with lowered thresholds, got %d", len(diags))
+	}
+}
+
+func TestAnalyzer_ResolvedValuesBad(t *testing.T) {
+	src := `package a
+
+import (
+	"flag"
+
+	"k8s.io/client-go/rest"
+)
+
+const defaultQPS = 1_000_000
+
+var qps = flag.Float64("qps", 5e6, "client QPS")
+
+type Options struct{ Burst int }
+
+func burst() int { return 2 * defaultQPS }
+
+func f(o Options) {
+	cfg := &rest.Config{QPS: defaultQPS, Burst: burst()}
+	cfg.QPS = float32(*qps)
+	cfg.Burst = o.Burst
+	_ = Options{Burst: 0}
+}
+`
+	diags := runAnalyzerOnSrc(t, src)
+	if len(diags) != 3 {
+		t.Fatalf("expected 3 diagnostics, got %d", len(diags))
+	}
+}
+
+func TestAnalyzer_ResolvedValuesGood_NoDiag(t *testing.T) {
+	src := `package a
+
+import (
+	"flag"
+
+	"k8s.io/client-go/rest"
+)
+
+const defaultQPS = 50
+
+var qps float64
+
+func init() { flag.Float64Var(&qps, "qps", 20, "client QPS") }
+
+func burst(scale int) int {
+	if scale > 1 {
+		return 100 * scale
+	}
+	return 100
+}
+
+func f(n int) {
+	cfg := &rest.Config{QPS: defaultQPS, Burst: burst(n)}
+	cfg.QPS = float32(qps)
+	cfg.Burst = n
+}
+`
+	diags := runAnalyzerOnSrc(t, src)
+	if len(diags) != 0 {
+		t.Fatalf("expected 0 diagnostics, got %d", len(diags))
+	}
+}
+
+func TestAnalyzer_ConfigReachesClientWithDefaults(t *testing.T) {
+	src := `package a
+
+import (
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+func f() {
+	cfg, _ := rest.InClusterConfig()
+	cfg.QPS = 50
+	_, _ = kubernetes.NewForConfig(cfg)
+	cfg.Burst = 100
+	_, _ = kubernetes.NewForConfig(rest.CopyConfig(cfg))
+
+	_, _ = ctrl.NewManager(ctrl.GetConfigOrDie(), manager.Options{})
+	_, _ = kubernetes.NewForConfig(&rest.Config{QPS: 50})
+}
+`
+	diags := runAnalyzerOnSrc(t, src)
+	if len(diags) != 2 {
+		t.Fatalf("expected 2 diagnostics for default Burst, got %d: %v", len(diags), diags)
+	}
+}
@@ -0,0 +1,207 @@
+package analyzers
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Category groups analyzers by the kind of problem they detect.
+type Category string
+
+const (
+	// CategoryLoad covers patterns that put avoidable load on the API server.
+	CategoryLoad Category = "load"
+	// CategoryCorrectness covers patterns that are likely bugs.
+	CategoryCorrectness Category = "correctness"
+	// CategorySecurity covers overly broad permissions and similar risks.
+	CategorySecurity Category = "security"
+	// CategoryResilience covers missing backoff, retries, timeouts and limits.
+	CategoryResilience Category = "resilience"
+)
+
+// Severity is how urgently a finding should be addressed.
+type Severity string
+
+const (
+	// SeverityError findings are likely to cause outages or expose clusters.
+	SeverityError Severity = "error"
+	// SeverityWarning findings are worth fixing but rarely urgent.
+	SeverityWarning Severity = "warning"
+	// SeverityInfo findings are hygiene and style suggestions.
+	SeverityInfo Severity = "info"
+)
+
+// Severities lists the severities from least to most severe.
+var Severities = []Severity{SeverityInfo, SeverityWarning, SeverityError}
+
+// ParseSeverity returns the severity named s.
+func ParseSeverity(s string) (Severity, error) {
+	if i := slices.Index(Severities, Severity(s)); i >= 0 {
+		return Severities[i], nil
+	}
+	return "", fmt.Errorf("unknown severity %q (want one of %v)", s, Severities)
+}
+
+// AtLeast reports whether s is at least as severe as min.
+func (s Severity) AtLeast(min Severity) bool {
+	return slices.Index(Severities, s) >= slices.Index(Severities, min)
+}
+
+// Maturity describes how much confidence we have in an analyzer's findings.
+type Maturity string
+
+const (
+	// MaturityStable analyzers have been vetted against real code bases.
+	MaturityStable Maturity = "stable"
+	// MaturityExperimental analyzers are heuristic and may be noisy.
+	MaturityExperimental Maturity = "experimental"
+)
+
+// Entry describes an analyzer known to the k8s-client-audit binary.
+type Entry struct {
+	Analyzer       *analysis.Analyzer
+	Category       Category
+	Severity       Severity
+	Maturity       Maturity
+	DefaultEnabled bool
+}
+
+// Name returns the analyzer name used in output and on the command line.
+func (e Entry) Name() string { return e.Analyzer.Name }
+
+// Registry lists every analyzer in this package. The binary is built from
+// this list, so new analyzers must be added here to be usable.
+var Registry = []Entry{
+	{Analyzer: AnalyzerClientReuse, Category: CategoryLoad, Severity: SeverityWarning, Maturity: MaturityStable, DefaultEnabled: true},
+	{Analyzer: AnalyzerDiscoveryFlood, Category: CategoryLoad, Severity: SeverityWarning, Maturity: MaturityStable, DefaultEnabled: true},
+	{Analyzer: AnalyzerDynamicOveruse, Category: CategoryCorrectness, Severity: SeverityInfo, Maturity: MaturityStable, DefaultEnabled: true},
+	{Analyzer: AnalyzerLargePageSizes, Category: CategoryLoad, Severity: SeverityWarning, Maturity: MaturityStable, DefaultEnabled: true},
+	{Analyzer: AnalyzerLeakyWatch, Category: CategoryCorrectness, Severity: SeverityError, Maturity: MaturityStable, DefaultEnabled: true},
+	// listinloop exports facts, so selecting it makes the driver load every
+	// dependency from source; see the README for the cost.
+	{Analyzer: AnalyzerListInLoop, Category: CategoryLoad, Severity: SeverityWarning, Maturity: MaturityStable, DefaultEnabled: true},
+	{Analyzer: AnalyzerManualPolling, Category: CategoryLoad, Severity: SeverityWarning, Maturity: MaturityStable, DefaultEnabled: true},
+	{Analyzer: AnalyzerMissingContext, Category: CategoryCorrectness, Severity: SeverityWarning, Maturity: MaturityStable, DefaultEnabled: true},
+	{Analyzer: AnalyzerMissingInformer, Category: CategoryLoad, Severity: SeverityWarning, Maturity: MaturityStable, DefaultEnabled: true},
+	{Analyzer: AnalyzerNoSelectors, Category: CategoryLoad, Severity: SeverityInfo, Maturity: MaturityStable, DefaultEnabled: true},
+	{Analyzer: AnalyzerQPSBurst, Category: CategoryLoad, Severity: SeverityWarning, Maturity: MaturityStable, DefaultEnabled: true},
+	{Analyzer: AnalyzerRESTMapperNotCached, Category: CategoryLoad, Severity: SeverityWarning, Maturity: MaturityStable, DefaultEnabled: true},
+	{Analyzer: AnalyzerRequeueBackoff, Category: CategoryResilience, Severity: SeverityWarning, Maturity: MaturityStable, DefaultEnabled: true},
+	{Analyzer: AnalyzerRestConfigDefaults, Category: CategoryResilience, Severity: SeverityInfo, Maturity: MaturityStable, DefaultEnabled: true},
+	{Analyzer: AnalyzerTightErrorLoops, Category: CategoryResilience, Severity: SeverityError, Maturity: MaturityStable, DefaultEnabled: true},
+	{Analyzer: AnalyzerUnboundedQueue, Category: CategoryResilience, Severity: SeverityWarning, Maturity: MaturityStable, DefaultEnabled: true},
+	{Analyzer: AnalyzerUnstructuredEverywhere, Category: CategoryCorrectness, Severity: SeverityInfo, Maturity: MaturityStable, DefaultEnabled: true},
+	{Analyzer: AnalyzerWideNamespace, Category: CategoryLoad, Severity: SeverityWarning, Maturity: MaturityStable, DefaultEnabled: true},
+
+	{Analyzer: AnalyzerBlockingHandler, Category: CategoryResilience, Severity: SeverityWarning, Maturity: MaturityExperimental},
+	{Analyzer: AnalyzerBroadWatch, Category: CategoryLoad, Severity: SeverityWarning, Maturity: MaturityExperimental},
+	{Analyzer: AnalyzerCacheMutation, Category: CategoryCorrectness, Severity: SeverityError, Maturity: MaturityExperimental},
+	{Analyzer: AnalyzerConflictRetry, Category: CategoryResilience, Severity: SeverityWarning, Maturity: MaturityExperimental},
+	{Analyzer: AnalyzerDuplicateInformers, Category: CategoryLoad, Severity: SeverityWarning, Maturity: MaturityExperimental},
+	{Analyzer: AnalyzerExcessiveClusterScope, Category: CategorySecurity, Severity: SeverityWarning, Maturity: MaturityExperimental},
+	{Analyzer: AnalyzerExcessiveConfig, Category: CategoryLoad, Severity: SeverityWarning, Maturity: MaturityExperimental},
+	{Analyzer: AnalyzerIgnoring429, Category: CategoryResilience, Severity: SeverityWarning, Maturity: MaturityExperimental},
+	{Analyzer: AnalyzerInformerStart, Category: CategoryCorrectness, Severity: SeverityWarning, Maturity: MaturityExperimental},
+	{Analyzer: AnalyzerMissingCacheSync, Category: CategoryCorrectness, Severity: SeverityWarning, Maturity: MaturityExperimental},
+	{Analyzer: AnalyzerNoResync, Category: CategoryResilience, Severity: SeverityInfo, Maturity: MaturityExperimental},
+	{Analyzer: AnalyzerNoRetryTransient, Category: CategoryResilience, Severity: SeverityWarning, Maturity: MaturityExperimental},
+	{Analyzer: AnalyzerNotFoundRequeue, Category: CategoryResilience, Severity: SeverityWarning, Maturity: MaturityExperimental},
+	{Analyzer: AnalyzerPreferPatch, Category: CategoryCorrectness, Severity: SeverityWarning, Maturity: MaturityExperimental},
+	{Analyzer: AnalyzerRestConfigMutation, Category: CategoryCorrectness, Severity: SeverityWarning, Maturity: MaturityExperimental},
+	{Analyzer: AnalyzerSelfReconcile, Category: CategoryLoad, Severity: SeverityWarning, Maturity: MaturityExperimental},
+	{Analyzer: AnalyzerTransportReuse, Category: CategoryLoad, Severity: SeverityWarning, Maturity: MaturityExperimental},
+	{Analyzer: AnalyzerUncachedReads, Category: CategoryLoad, Severity: SeverityWarning, Maturity: MaturityExperimental},
+	{Analyzer: AnalyzerWildcardVerbs, Category: CategorySecurity, Severity: SeverityError, Maturity: MaturityExperimental},
+}
+
+// SeverityOf returns the severity of the named analyzer, or SeverityWarning
+// for analyzers outside the registry.
+func SeverityOf(name string) Severity {
+	if e, ok := Lookup(name); ok {
+		return e.Severity
+	}
+	return SeverityWarning
+}
+
+// Lookup returns the registry entry for the named analyzer.
+func Lookup(name string) (Entry, bool) {
+	for _, e := range Registry {
+		if e.Name() == name {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Selection controls which registry entries are run.
+type Selection struct {
+	// Experimental enables every experimental analyzer.
+	Experimental bool
+	// Enable lists analyzers to run in addition to the defaults.
+	Enable []string
+	// Disable lists analyzers to skip. It takes precedence over Enable.
+	Disable []string
+}
+
+// Override returns s with o layered on top: o may turn on the experimental
+// group, and an analyzer named in one of o's lists overrides s's choice for it.
+func (s Selection) Override(o Selection) Selection {
+	out := Selection{Experimental: s.Experimental || o.Experimental}
+	drop := func(list, remove []string) []string {
+		var kept []string
+		for _, name := range list {
+			if !slices.Contains(remove, name) {
+				kept = append(kept, name)
+			}
+		}
+		return kept
+	}
+	out.Enable = append(drop(s.Enable, o.Disable), o.Enable...)
+	out.Disable = append(drop(s.Disable, o.Enable), o.Disable...)
+	return out
+}
+
+// Select returns the analyzers chosen by sel, in registry order. Unknown
+// analyzer names are reported as an error so typos do not go unnoticed.
+func Select(entries []Entry, sel Selection) ([]*analysis.Analyzer, error) {
+	known := map[string]bool{}
+	for _, e := range entries {
+		known[e.Name()] = true
+	}
+	enabled := map[string]bool{}
+	for _, e := range entries {
+		if e.DefaultEnabled || (sel.Experimental && e.Maturity == MaturityExperimental) {
+			enabled[e.Name()] = true
+		}
+	}
+	var unknown []string
+	for _, name := range sel.Enable {
+		if !known[name] {
+			unknown = append(unknown, name)
+			continue
+		}
+		enabled[name] = true
+	}
+	for _, name := range sel.Disable {
+		if !known[name] {
+			unknown = append(unknown, name)
+			continue
+		}
+		delete(enabled, name)
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown analyzers: %v", unknown)
+	}
+
+	var out []*analysis.Analyzer
+	for _, e := range entries {
+		if enabled[e.Name()] {
+			out = append(out, e.Analyzer)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,105 @@
+package analyzers
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+func analyzerNames(as []*analysis.Analyzer) map[string]bool {
+	names := map[string]bool{}
+	for _, a := range as {
+		names[a.Name] = true
+	}
+	return names
+}
+
+func TestRegistry_ValidAndUnique(t *testing.T) {
+	var all []*analysis.Analyzer
+	seen := map[string]bool{}
+	for _, e := range Registry {
+		if seen[e.Name()] {
+			t.Fatalf("analyzer %q registered twice", e.Name())
+		}
+		seen[e.Name()] = true
+		if e.Category == "" || e.Maturity == "" {
+			t.Fatalf("analyzer %q is missing a category or maturity", e.Name())
+		}
+		if _, err := ParseSeverity(string(e.Severity)); err != nil {
+			t.Fatalf("analyzer %q: %v", e.Name(), err)
+		}
+		if e.Maturity == MaturityExperimental && e.DefaultEnabled {
+			t.Fatalf("experimental analyzer %q must not be enabled by default", e.Name())
+		}
+		all = append(all, e.Analyzer)
+	}
+	if err := analysis.Validate(all); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+}
+
+func TestSelect_DefaultsExcludeExperimental(t *testing.T) {
+	got, err := Select(Registry, Selection{})
+	if err != nil {
+		t.Fatalf("select: %v", err)
+	}
+	names := analyzerNames(got)
+	if !names["listinloop"] {
+		t.Fatalf("expected stable analyzer listinloop to be selected")
+	}
+	if names["wildcardverbs"] {
+		t.Fatalf("did not expect experimental analyzer wildcardverbs by default")
+	}
+}
+
+func TestSelect_ExperimentalGroup(t *testing.T) {
+	got, err := Select(Registry, Selection{Experimental: true})
+	if err != nil {
+		t.Fatalf("select: %v", err)
+	}
+	if len(got) != len(Registry) {
+		t.Fatalf("expected all %d analyzers, got %d", len(Registry), len(got))
+	}
+}
+
+func TestSelect_EnableDisable(t *testing.T) {
+	got, err := Select(Registry, Selection{Enable: []string{"noresync"}, Disable: []string{"listinloop", "noresync"}})
+	if err != nil {
+		t.Fatalf("select: %v", err)
+	}
+	names := analyzerNames(got)
+	if names["listinloop"] || names["noresync"] {
+		t.Fatalf("disable should take precedence, got %v", names)
+	}
+}
+
+func TestSelect_UnknownName(t *testing.T) {
+	if _, err := Select(Registry, Selection{Enable: []string{"nosuchanalyzer"}}); err == nil {
+		t.Fatalf("expected error for unknown analyzer")
+	}
+}
+
+func TestSelection_Override(t *testing.T) {
+	base := Selection{Enable: []string{"noresync"}, Disable: []string{"listinloop"}}
+	got := base.Override(Selection{Enable: []string{"listinloop"}, Disable: []string{"noresync"}})
+	sel, err := Select(Registry, got)
+	if err != nil {
+		t.Fatalf("select: %v", err)
+	}
+	names := analyzerNames(sel)
+	if !names["listinloop"] || names["noresync"] {
+		t.Fatalf("expected the override to win, got %v", names)
+	}
+}
+
+func TestSeverity_AtLeast(t *testing.T) {
+	if !SeverityError.AtLeast(SeverityWarning) || !SeverityWarning.AtLeast(SeverityWarning) {
+		t.Fatalf("expected error and warning to be at least warning")
+	}
+	if SeverityInfo.AtLeast(SeverityWarning) {
+		t.Fatalf("did not expect info to be at least warning")
+	}
+	if _, err := ParseSeverity("critical"); err == nil {
+		t.Fatalf("expected error for unknown severity")
+	}
+}
@@ -2,20 +2,29 @@ package analyzers
 
 import (
 	"go/ast"
+	"go/constant"
+	"go/token"
 	"go/types"
 
 	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
 	insppass "golang.org/x/tools/go/analysis/passes/inspect"
 	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/ssa"
 )
 
 // AnalyzerRequeueBackoff flags controller-runtime Reconcile paths that
 // requeue immediately without a backoff (e.g., returning requeue=true without RequeueAfter).
+//
+// It also checks the full return tuple of Reconcile methods, including bare
+// returns of named results: controller-runtime ignores the Result when the
+// error is not nil, so a Requeue or RequeueAfter returned with an error, or
+// with a reconcile.TerminalError, is discarded.
 var AnalyzerRequeueBackoff = &analysis.Analyzer{
 	Name:     "requeuebackoff",
-	Doc:      "flags requeue without backoff in Reconcile",
+	Doc:      "flags requeue without backoff in Reconcile, and requeue Results returned with an error",
 	Run:      runRequeueBackoff,
-	Requires: []*analysis.Analyzer{insppass.Analyzer},
+	Requires: []*analysis.Analyzer{insppass.Analyzer, buildssa.Analyzer},
 }
 
 func runRequeueBackoff(pass *analysis.Pass) (any, error) {
@@ -54,7 +63,7 @@ func runRequeueBackoff(pass *analysis.Pass) (any, error) {
 			// Use type information to verify this is a controller-runtime Result
 			if t := pass.TypesInfo.TypeOf(cl); t != nil {
 				if isControllerRuntimeResult(t) {
-					hasRequeue := false
+					var requeue *ast.KeyValueExpr
 					hasRequeueAfter := false
 
 					// Check the fields of the composite literal
@@ -62,7 +71,7 @@ func runRequeueBackoff(pass *analysis.Pass) (any, error) {
 						if kv, ok := el.(*ast.KeyValueExpr); ok {
 							if k, ok := kv.Key.(*ast.Ident); ok {
 								if k.Name == "Requeue" {
-									hasRequeue = true
+									requeue = kv
 								}
 								if k.Name == "RequeueAfter" {
 									hasRequeueAfter = true
@@ -71,8 +80,12 @@ func runRequeueBackoff(pass *analysis.Pass) (any, error) {
 						}
 					}
 
-					if hasRequeue && !hasRequeueAfter {
-						pass.Reportf(ret.Return, "controller-runtime Requeue without backoff; prefer RequeueAfter with delay or rate-limited queue")
+					if requeue != nil && !hasRequeueAfter {
+						pass.Report(analysis.Diagnostic{
+							Pos:            ret.Return,
+							Message:        "controller-runtime Requeue without backoff; prefer RequeueAfter with delay or rate-limited queue",
+							SuggestedFixes: requeueAfterFix(pass, requeue),
+						})
 					}
 				}
 			}
@@ -81,5 +94,160 @@ func runRequeueBackoff(pass *analysis.Pass) (any, error) {
 		return true
 	})
 
+	ssaInfo := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+	for _, fn := range ssaInfo.SrcFuncs {
+		if !isReconcileFunc(fn.Object()) {
+			continue
+		}
+		for _, b := range fn.Blocks {
+			ret, ok := b.Instrs[len(b.Instrs)-1].(*ssa.Return)
+			if !ok || len(ret.Results) != 2 {
+				continue
+			}
+			field := resultRequeue(ret.Results[0], ret, map[ssa.Value]bool{})
+			if field == "" {
+				continue
+			}
+			switch err := ret.Results[1]; {
+			case isTerminalError(err):
+				pass.Reportf(ret.Pos(), "Reconcile returns a Result with %s and a reconcile.TerminalError; terminal errors are never requeued, so the Result is ignored", field)
+			case mayBeNonNil(err, map[ssa.Value]bool{}):
+				pass.Reportf(ret.Pos(), "Reconcile returns a Result with %s and an error; controller-runtime ignores the Result when the error is not nil, so return a nil error to requeue after the delay, or wrap the error in reconcile.TerminalError if it should not be retried", field)
+			}
+		}
+	}
+
 	return nil, nil
 }
+
+// resultRequeue returns the field, Requeue or RequeueAfter, set in the
+// reconcile.Result v returned by ret, or "". Stores into a Result variable
+// are considered when they can execute before ret.
+func resultRequeue(v ssa.Value, ret *ssa.Return, seen map[ssa.Value]bool) string {
+	if seen[v] {
+		return ""
+	}
+	seen[v] = true
+	switch v := v.(type) {
+	case *ssa.Phi:
+		for _, e := range v.Edges {
+			if field := resultRequeue(e, ret, seen); field != "" {
+				return field
+			}
+		}
+	case *ssa.UnOp:
+		alloc, ok := v.X.(*ssa.Alloc)
+		if v.Op != token.MUL || !ok {
+			return ""
+		}
+		for _, ref := range *alloc.Referrers() {
+			switch ref := ref.(type) {
+			case *ssa.FieldAddr:
+				f := structField(deref(alloc.Type()), ref.Field)
+				if f == nil || (f.Name() != "Requeue" && f.Name() != "RequeueAfter") {
+					continue
+				}
+				for _, r := range *ref.Referrers() {
+					if st, ok := r.(*ssa.Store); ok && st.Addr == ref && !isZeroConst(st.Val) && reaches(st.Block(), ret.Block()) {
+						return f.Name()
+					}
+				}
+			case *ssa.Store:
+				if ref.Addr == alloc && reaches(ref.Block(), ret.Block()) {
+					if field := resultRequeue(ref.Val, ret, seen); field != "" {
+						return field
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// isZeroConst reports whether v is a constant zero value: false, 0, "" or nil.
+func isZeroConst(v ssa.Value) bool {
+	c, ok := v.(*ssa.Const)
+	if !ok {
+		return false
+	}
+	switch {
+	case c.Value == nil:
+		return true
+	case c.Value.Kind() == constant.Bool:
+		return !constant.BoolVal(c.Value)
+	case c.Value.Kind() == constant.String:
+		return constant.StringVal(c.Value) == ""
+	}
+	return constant.Sign(c.Value) == 0
+}
+
+// isTerminalError reports whether err is a reconcile.TerminalError.
+func isTerminalError(err ssa.Value) bool {
+	call, ok := objectRoot(err).(*ssa.Call)
+	if !ok {
+		return false
+	}
+	fn := ssaCallee(&call.Call)
+	return fn != nil && fn.Pkg() != nil && fn.Pkg().Path() == PkgControllerRuntimeReconcile && fn.Name() == "TerminalError"
+}
+
+// mayBeNonNil reports whether err may hold an error: it is not the nil
+// constant, nor a phi of values that are all nil.
+func mayBeNonNil(err ssa.Value, seen map[ssa.Value]bool) bool {
+	if seen[err] {
+		return false
+	}
+	seen[err] = true
+	switch err := err.(type) {
+	case *ssa.Const:
+		return !err.IsNil()
+	case *ssa.Phi:
+		for _, e := range err.Edges {
+			if mayBeNonNil(e, seen) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// reaches reports whether control can flow from block from to block to.
+func reaches(from, to *ssa.BasicBlock) bool {
+	seen := map[*ssa.BasicBlock]bool{}
+	stack := []*ssa.BasicBlock{from}
+	for len(stack) > 0 {
+		b := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if b == to {
+			return true
+		}
+		if seen[b] {
+			continue
+		}
+		seen[b] = true
+		stack = append(stack, b.Succs...)
+	}
+	return false
+}
+
+// requeueAfterFix rewrites "Requeue: true" to a RequeueAfter with a fixed
+// delay. Requeue set from an expression is left alone, as the condition would
+// have to move into the delay.
+func requeueAfterFix(pass *analysis.Pass, requeue *ast.KeyValueExpr) []analysis.SuggestedFix {
+	if v, ok := requeue.Value.(*ast.Ident); !ok || v.Name != "true" {
+		return nil
+	}
+	file := enclosingFile(pass, requeue.Pos())
+	if file == nil {
+		return nil
+	}
+	timePkg, edits := importQualifier(file, "time")
+	delay := "5 * " + qualify(timePkg, "Second")
+	edits = append(edits, analysis.TextEdit{
+		Pos:     requeue.Pos(),
+		End:     requeue.End(),
+		NewText: []byte("RequeueAfter: " + delay),
+	})
+	return []analysis.SuggestedFix{{Message: "Requeue after a 5s delay", TextEdits: edits}}
+}
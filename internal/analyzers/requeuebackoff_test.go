@@ -1,6 +1,7 @@
 package analyzers
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/amisstea/k8s-client-audit/internal/analyzers/testutil"
@@ -8,36 +9,46 @@ import (
 	"golang.org/x/tools/go/analysis"
 )
 
-func runRequeueBackoffAnalyzerOnSrc(t *testing.T, src string, spoofControllerRuntimeTypes bool) []analysis.Diagnostic {
+func runRequeueBackoffAnalyzerOnSrc(t *testing.T, src string) []analysis.Diagnostic {
 	t.Helper()
-	var diags []analysis.Diagnostic
-	var err error
-	if spoofControllerRuntimeTypes {
-		diags, err = testutil.RunAnalyzerOnSrc(AnalyzerRequeueBackoff, src, testutil.SpoofControllerRuntimeResult)
-	} else {
-		diags, err = testutil.RunAnalyzerOnSrc(AnalyzerRequeueBackoff, src)
-	}
+	res, err := testutil.RunAnalyzerOnStubbedSrc(AnalyzerRequeueBackoff, src)
 	if err != nil {
 		t.Fatalf("run: %v", err)
 	}
-	return diags
+	return res.Diagnostics
 }
 
+const requeueBackoffImports = `package p
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var (
+	_ = context.TODO
+	_ = errors.New
+	_ = time.Second
+	_ reconcile.Result
+)
+`
+
 func TestRequeueBackoff_RequeueWithoutAfter_Flagged(t *testing.T) {
-	src := `package a
-type Result struct{ Requeue bool; RequeueAfter int }
-func f() (Result, error) { return Result{Requeue:true}, nil }`
-	diags := runRequeueBackoffAnalyzerOnSrc(t, src, true) // spoof as controller-runtime types
+	src := requeueBackoffImports + `
+func f() (reconcile.Result, error) { return reconcile.Result{Requeue:true}, nil }`
+	diags := runRequeueBackoffAnalyzerOnSrc(t, src)
 	if len(diags) == 0 {
 		t.Fatalf("expected diagnostic for controller-runtime requeue without backoff")
 	}
 }
 
 func TestRequeueBackoff_WithRequeueAfter_NoDiag(t *testing.T) {
-	src := `package a
-type Result struct{ Requeue bool; RequeueAfter int }
-func f() (Result, error) { return Result{Requeue:true, RequeueAfter:5}, nil }`
-	diags := runRequeueBackoffAnalyzerOnSrc(t, src, true) // spoof as controller-runtime types
+	src := requeueBackoffImports + `
+func f() (reconcile.Result, error) { return reconcile.Result{Requeue:true, RequeueAfter:5}, nil }`
+	diags := runRequeueBackoffAnalyzerOnSrc(t, src)
 	if len(diags) != 0 {
 		t.Fatalf("did not expect diagnostic when RequeueAfter is set")
 	}
@@ -47,18 +58,81 @@ func TestRequeueBackoff_NonControllerRuntimeResult_NoDiag(t *testing.T) {
 	src := `package a
 type Result struct{ Requeue bool; RequeueAfter int }
 func f() (Result, error) { return Result{Requeue:true}, nil }`
-	diags := runRequeueBackoffAnalyzerOnSrc(t, src, false) // don't spoof - should be treated as non-controller-runtime
+	diags := runRequeueBackoffAnalyzerOnSrc(t, src) // local type, not controller-runtime's
 	if len(diags) != 0 {
 		t.Fatalf("expected 0 diagnostics for non-controller-runtime Result types, got %d", len(diags))
 	}
 }
 
 func TestRequeueBackoff_NoRequeue_NoDiag(t *testing.T) {
-	src := `package a
-type Result struct{ Requeue bool; RequeueAfter int }
-func f() (Result, error) { return Result{}, nil }`
-	diags := runRequeueBackoffAnalyzerOnSrc(t, src, true) // spoof as controller-runtime types
+	src := requeueBackoffImports + `
+func f() (reconcile.Result, error) { return reconcile.Result{}, nil }`
+	diags := runRequeueBackoffAnalyzerOnSrc(t, src)
 	if len(diags) != 0 {
 		t.Fatalf("expected 0 diagnostics when Requeue is not set, got %d", len(diags))
 	}
 }
+
+func TestRequeueBackoff_RequeueWithError_Flagged(t *testing.T) {
+	src := requeueBackoffImports + `
+type R struct{}
+
+func (r *R) sync(ctx context.Context) error { return errors.New("sync") }
+
+func (r *R) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	if err := r.sync(ctx); err != nil {
+		return reconcile.Result{RequeueAfter: time.Minute}, err
+	}
+	if req.Name == "" {
+		return reconcile.Result{RequeueAfter: time.Minute}, reconcile.TerminalError(errors.New("no name"))
+	}
+	return reconcile.Result{RequeueAfter: time.Hour}, nil
+}
+
+type Named struct{}
+
+func (n *Named) Reconcile(ctx context.Context, req reconcile.Request) (res reconcile.Result, err error) {
+	if req.Name == "" {
+		return
+	}
+	if err = errors.New("failed"); err != nil {
+		res.RequeueAfter = time.Minute
+		return
+	}
+	return
+}`
+	diags := runRequeueBackoffAnalyzerOnSrc(t, src)
+	if len(diags) != 3 {
+		t.Fatalf("expected 3 diagnostics, got %v", diags)
+	}
+	if !strings.Contains(diags[1].Message, "TerminalError") {
+		t.Fatalf("expected a TerminalError diagnostic, got %q", diags[1].Message)
+	}
+}
+
+func TestRequeueBackoff_SuggestedFix_RequeueAfter(t *testing.T) {
+	src := `package p
+
+import "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+func f(done bool) (reconcile.Result, error) {
+	if done {
+		return reconcile.Result{Requeue: done}, nil
+	}
+	return reconcile.Result{Requeue: true}, nil
+}
+`
+	res, err := testutil.RunAnalyzerOnStubbedSrc(AnalyzerRequeueBackoff, src)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(res.Diagnostics) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %d", len(res.Diagnostics))
+	}
+	fixed := testutil.MustApplyFixes(t, res)["p"]
+	for _, want := range []string{`import "time"`, "reconcile.Result{RequeueAfter: 5 * time.Second}", "reconcile.Result{Requeue: done}"} {
+		if !strings.Contains(fixed, want) {
+			t.Fatalf("expected fixed source to contain %q:\n%s", want, fixed)
+		}
+	}
+}
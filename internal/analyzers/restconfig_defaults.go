@@ -2,100 +2,116 @@ package analyzers
 
 import (
 	"go/ast"
+	"go/constant"
+	"go/token"
 	"go/types"
 
 	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
 	"golang.org/x/tools/go/analysis/passes/inspect"
 	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/ssa"
 )
 
-// AnalyzerRestConfigDefaults flags rest.Config creations missing
-// timeouts or user-agent.
+// AnalyzerRestConfigDefaults flags rest.Config values reaching a client
+// constructor without a Timeout or UserAgent.
+//
+// Each config created in the package, as a literal or by rest.InClusterConfig,
+// a clientcmd loader or controller-runtime's GetConfig, is followed through
+// its field assignments and copies to the constructors it is passed to, like
+// kubernetes.NewForConfig or ctrl.NewManager. Fields assigned after the
+// constructor call, or assigned a value resolving to zero, are still default.
+// rest.Config literals that reach no constructor in the package, like configs
+// returned to other packages, are checked on their own: a Timeout resolving to
+// zero, and a missing Timeout or UserAgent, are reported.
 var AnalyzerRestConfigDefaults = &analysis.Analyzer{
 	Name:     "restconfigdefaults",
 	Doc:      "flags rest.Config initialization without timeouts or UserAgent",
 	Run:      runRestConfigDefaults,
-	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Requires: []*analysis.Analyzer{inspect.Analyzer, buildssa.Analyzer},
 }
 
 func runRestConfigDefaults(pass *analysis.Pass) (any, error) {
-	// Get the inspector from the analysis pass.
-	// The inspector is configured to visit all nodes in the AST.
-	inspector := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	consts := newConstTracer(pass)
+	flows := restConfigFlows(pass, consts)
+	reportUnsetRestConfigFields(pass, flows, "set sane defaults", "Timeout", "UserAgent")
 
-	// Define the types of AST nodes we want to inspect.
-	// We are interested in CompositeLiterals, which represent struct instantiations.
-	// e.g., rest.Config{...} or &rest.Config{...}
-	nodeFilter := []ast.Node{
-		(*ast.CompositeLit)(nil),
-	}
-
-	// The inspector's Preorder function traverses the AST.
-	// It calls the provided function for each node that matches the filter.
-	inspector.Preorder(nodeFilter, func(n ast.Node) {
-		// Assert that the node is a CompositeLit.
-		compLit, ok := n.(*ast.CompositeLit)
-		if !ok {
-			return // Should not happen due to the node filter
+	// Literals and variables whose config, or a copy of it, reaches a client
+	// constructor were checked above.
+	reached := map[token.Pos]bool{}
+	for _, f := range flows {
+		if len(f.sinks) == 0 {
+			continue
 		}
-
-		// Get the type information for the composite literal.
-		// pass.TypesInfo.TypeOf returns the type of an expression.
-		typ := pass.TypesInfo.TypeOf(compLit)
-		if typ == nil {
-			return
+		for ; f != nil; f = f.parent {
+			if alloc, ok := f.source.(*ssa.Alloc); ok {
+				reached[alloc.Pos()] = true
+			}
 		}
+	}
 
-		// We need to handle both `rest.Config{}` and `&rest.Config{}`.
-		// If it's a pointer, we get the underlying element type.
-		if ptr, ok := typ.(*types.Pointer); ok {
-			typ = ptr.Elem()
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	insp.WithStack([]ast.Node{(*ast.CompositeLit)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		lit := n.(*ast.CompositeLit)
+		if !push || !isNamed(deref(pass.TypesInfo.TypeOf(lit)), PkgClientGoRest, "Config") {
+			return true
 		}
-
-		// Check if the type is a named type (like a struct).
-		named, ok := typ.(*types.Named)
-		if !ok {
-			return
+		// A &rest.Config{} literal is allocated at its brace, a variable
+		// assigned a rest.Config{} literal at its declaration.
+		if reached[lit.Lbrace] || reached[initializedVar(pass.TypesInfo, lit, stack)] {
+			return true
 		}
-
-		// Check if the type object and its package match what we're looking for.
-		// named.Obj() gives us the TypeName object.
-		// named.Obj().Pkg() gives us the package of the type.
-		// named.Obj().Name() gives us the name of the type.
-		if named.Obj() != nil && named.Obj().Pkg() != nil &&
-			named.Obj().Pkg().Path() == "k8s.io/client-go/rest" &&
-			named.Obj().Name() == "Config" {
-
-			// Track whether Timeout and UserAgent fields are set in the rest.Config composite literal.
-			var hasTimeout, hasUserAgent bool
-
-			// Iterate over each element in the composite literal (i.e., each field assignment).
-			for _, el := range compLit.Elts {
-				// We are only interested in key-value pairs (e.g., Timeout: ..., UserAgent: ...).
-				kv, ok := el.(*ast.KeyValueExpr)
-				if !ok {
-					continue
-				}
-				// Check if the key is an identifier (the field name).
-				switch k := kv.Key.(type) {
-				case *ast.Ident:
-					switch k.Name {
-					case "Timeout":
-						hasTimeout = true
-						// Check if the Timeout value is a basic literal and is set to zero.
-						if bl, ok := kv.Value.(*ast.BasicLit); ok && bl.Value == "0" {
-							pass.Reportf(k.Pos(), "rest.Config Timeout is zero; set a reasonable timeout")
-						}
-					case "UserAgent":
-						hasUserAgent = true
-					}
-				}
+		var hasTimeout, hasUserAgent bool
+		for _, el := range lit.Elts {
+			kv, ok := el.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			k, ok := kv.Key.(*ast.Ident)
+			if !ok {
+				continue
 			}
-			if !hasTimeout || !hasUserAgent {
-				pass.Reportf(n.Pos(), "rest.Config missing Timeout and/or UserAgent; set sane defaults")
+			switch k.Name {
+			case "Timeout":
+				hasTimeout = true
+				if c := consts.exprValue(kv.Value, kv.Colon); c != nil && c.Kind() == constant.Int && constant.Sign(c) == 0 {
+					pass.Reportf(k.Pos(), "rest.Config Timeout is zero; set a reasonable timeout")
+				}
+			case "UserAgent":
+				hasUserAgent = true
 			}
 		}
+		if !hasTimeout || !hasUserAgent {
+			pass.Reportf(lit.Pos(), "rest.Config missing Timeout and/or UserAgent; set sane defaults")
+		}
+		return true
 	})
-
 	return nil, nil
 }
+
+// initializedVar returns the declaration of the variable that the composite
+// literal lit, with its enclosing nodes in stack, is assigned to, or
+// token.NoPos.
+func initializedVar(info *types.Info, lit *ast.CompositeLit, stack []ast.Node) token.Pos {
+	if len(stack) < 2 {
+		return token.NoPos
+	}
+	switch parent := stack[len(stack)-2].(type) {
+	case *ast.AssignStmt:
+		for i, rhs := range parent.Rhs {
+			if rhs != lit || i >= len(parent.Lhs) {
+				continue
+			}
+			if id, ok := parent.Lhs[i].(*ast.Ident); ok && info.ObjectOf(id) != nil {
+				return info.ObjectOf(id).Pos()
+			}
+		}
+	case *ast.ValueSpec:
+		for i, v := range parent.Values {
+			if v == lit && i < len(parent.Names) {
+				return parent.Names[i].Pos()
+			}
+		}
+	}
+	return token.NoPos
+}
@@ -1,6 +1,7 @@
 package analyzers
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/amisstea/k8s-client-audit/internal/analyzers/testutil"
@@ -8,25 +9,25 @@ import (
 	"golang.org/x/tools/go/analysis"
 )
 
-// NOTE: The analyzer under test expects the *real* rest.Config type from k8s.io/client-go/rest,
-// not a local stub. So we must import the real type and use it in the test source.
-
 func runRestConfigDefaultsAnalyzerOnSrc(t *testing.T, src string) []analysis.Diagnostic {
 	t.Helper()
-	diags, err := testutil.RunAnalyzerOnSrc(AnalyzerRestConfigDefaults, src, testutil.SpoofRestConfig)
+	res, err := testutil.RunAnalyzerOnStubbedSrc(AnalyzerRestConfigDefaults, src)
 	if err != nil {
 		t.Fatalf("run: %v", err)
 	}
-	return diags
+	return res.Diagnostics
 }
 
 func TestRestConfigDefaults_MissingFields_Flagged(t *testing.T) {
 	src := `
 package a
 
-import "k8s.io/client-go/rest"
+import (
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
 
-var _ = rest.Config{}
+var _, _ = kubernetes.NewForConfig(&rest.Config{})
 `
 	diags := runRestConfigDefaultsAnalyzerOnSrc(t, src)
 	if len(diags) == 0 {
@@ -38,9 +39,12 @@ func TestRestConfigDefaults_ZeroTimeout_EmptyUA_Flagged(t *testing.T) {
 	src := `
 package a
 
-import "k8s.io/client-go/rest"
+import (
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
 
-var _ = rest.Config{Timeout:0, UserAgent:""}
+var _, _ = kubernetes.NewForConfig(&rest.Config{Timeout:0, UserAgent:""})
 `
 	diags := runRestConfigDefaultsAnalyzerOnSrc(t, src)
 	if len(diags) == 0 {
@@ -48,16 +52,153 @@ var _ = rest.Config{Timeout:0, UserAgent:""}
 	}
 }
 
+func TestRestConfigDefaults_ResolvedZeroTimeout_Flagged(t *testing.T) {
+	src := `
+package a
+
+import (
+	"flag"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const noTimeout = 0 * time.Second
+
+var timeout = flag.Duration("timeout", 0, "request timeout")
+
+func defaultTimeout() time.Duration { return noTimeout }
+
+func f() {
+	_, _ = kubernetes.NewForConfig(&rest.Config{Timeout: noTimeout, UserAgent: "a"})
+	_, _ = kubernetes.NewForConfig(&rest.Config{Timeout: *timeout, UserAgent: "a"})
+	_, _ = kubernetes.NewForConfig(&rest.Config{Timeout: defaultTimeout(), UserAgent: "a"})
+}
+`
+	diags := runRestConfigDefaultsAnalyzerOnSrc(t, src)
+	if len(diags) != 3 {
+		t.Fatalf("expected 3 diagnostics for zero timeouts, got %d", len(diags))
+	}
+}
+
+func TestRestConfigDefaults_ConfigLifetime_Flagged(t *testing.T) {
+	src := `
+package a
+
+import (
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func loaded() {
+	cfg, _ := clientcmd.BuildConfigFromFlags("", "kubeconfig")
+	_, _ = kubernetes.NewForConfig(cfg)
+	cfg.Timeout = time.Minute
+	cfg.UserAgent = "op"
+}
+
+func copied() {
+	cfg := ctrl.GetConfigOrDie()
+	cfg.UserAgent = "op"
+	cp := *cfg
+	_, _ = client.New(&cp, client.Options{})
+}
+
+func newConfig() *rest.Config { return &rest.Config{Timeout: time.Minute} }
+
+var clientset, _ = kubernetes.NewForConfig(newConfig())
+`
+	diags := runRestConfigDefaultsAnalyzerOnSrc(t, src)
+	if len(diags) != 3 {
+		t.Fatalf("expected 3 diagnostics, got %d: %v", len(diags), diags)
+	}
+	for i, want := range []string{
+		"from clientcmd.BuildConfigFromFlags reaches kubernetes.NewForConfig with default Timeout and UserAgent",
+		"from a copy at p.go:24 reaches client.New with default Timeout;",
+		"from p.go:28 reaches kubernetes.NewForConfig with default UserAgent;",
+	} {
+		if !strings.Contains(diags[i].Message, want) {
+			t.Errorf("diagnostic %d: expected %q in %q", i, want, diags[i].Message)
+		}
+	}
+}
+
+func TestRestConfigDefaults_UnreachedLiterals_Flagged(t *testing.T) {
+	src := `
+package a
+
+import (
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+const noTimeout = 0 * time.Second
+
+var _ = rest.Config{}
+
+func NewConfig(host string) *rest.Config {
+	return &rest.Config{Host: host, Timeout: noTimeout, UserAgent: "op"}
+}
+
+func complete() rest.Config {
+	cfg := rest.Config{Timeout: time.Minute, UserAgent: "op"}
+	return cfg
+}
+`
+	diags := runRestConfigDefaultsAnalyzerOnSrc(t, src)
+	if len(diags) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %d: %v", len(diags), diags)
+	}
+	for i, want := range []string{
+		"rest.Config missing Timeout and/or UserAgent",
+		"rest.Config Timeout is zero",
+	} {
+		if !strings.Contains(diags[i].Message, want) {
+			t.Errorf("diagnostic %d: expected %q in %q", i, want, diags[i].Message)
+		}
+	}
+}
+
 func TestRestConfigDefaults_WithValues_NoDiag(t *testing.T) {
 	src := `
 package a
 
-import "k8s.io/client-go/rest"
+import (
+	"time"
 
-var _ = rest.Config{Timeout:10, UserAgent:"my-agent"}
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+func timeout(d time.Duration) time.Duration { return d }
+
+var _, _ = kubernetes.NewForConfig(&rest.Config{Timeout:10, UserAgent:"my-agent"})
+var _, _ = kubernetes.NewForConfig(&rest.Config{Timeout:timeout(30 * time.Second), UserAgent:"my-agent"})
+
+func configure(c *rest.Config) { c.UserAgent = "op" }
+
+func f() {
+	cfg, err := rest.InClusterConfig()
+	if err != nil {
+		panic(err)
+	}
+	cfg.Timeout = 30 * time.Second
+	configure(cfg)
+	_, _ = kubernetes.NewForConfig(cfg)
+	_, _ = ctrl.NewManager(rest.CopyConfig(cfg), manager.Options{})
+}
 `
 	diags := runRestConfigDefaultsAnalyzerOnSrc(t, src)
 	if len(diags) != 0 {
-		t.Fatalf("did not expect diagnostic when fields are set, got %d", len(diags))
+		t.Fatalf("did not expect diagnostic when fields are set, got %d: %v", len(diags), diags)
 	}
 }
@@ -0,0 +1,201 @@
+package analyzers
+
+import (
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+	"golang.org/x/tools/go/ssa"
+)
+
+// AnalyzerRestConfigMutation flags writes to a *rest.Config that has already
+// been passed to a client constructor, and writes to a config shared between
+// goroutines.
+//
+// Constructors like kubernetes.NewForConfig copy some fields and keep others,
+// such as WrapTransport, so setting QPS or Impersonate afterwards changes some
+// clients and not others. Configs are followed as for restconfigdefaults, and
+// also from parameters and from calls like mgr.GetConfig(). A field write is
+// reported if it may execute after a call of a constructor recognized by
+// isKubernetesClientConstructor, in the same function or through the functions
+// calling them, or if it is made in a goroutine on a config created outside
+// it that reaches a constructor.
+var AnalyzerRestConfigMutation = &analysis.Analyzer{
+	Name:     "restconfigmutation",
+	Doc:      "flags rest.Config fields written after the config was passed to a client constructor or from goroutines sharing it",
+	Run:      runRestConfigMutation,
+	Requires: []*analysis.Analyzer{buildssa.Analyzer},
+}
+
+// restConfigRoot is a config and the values it flows into. home is the
+// function it comes from, and fresh is set if it is created there.
+type restConfigRoot struct {
+	home    *ssa.Function
+	aliases []ssa.Value
+	fresh   bool
+}
+
+// restConfigWrite is a write of a top-level field of a config.
+type restConfigWrite struct {
+	instr ssa.Instruction
+	field string
+}
+
+func runRestConfigMutation(pass *analysis.Pass) (any, error) {
+	m := newRestConfigModel(pass, newConstTracer(pass))
+
+	covered := map[ssa.Value]bool{}
+	var roots []restConfigRoot
+	addRoot := func(src ssa.Value, aliases []ssa.Value, fresh bool) {
+		for _, v := range aliases {
+			covered[v] = true
+		}
+		roots = append(roots, restConfigRoot{home: src.Parent(), aliases: aliases, fresh: fresh})
+	}
+	for _, f := range m.flows {
+		addRoot(f.source, f.aliases, true)
+	}
+	// Configs from elsewhere: parameters, results of other calls like
+	// mgr.GetConfig() and loads of fields set outside the package.
+	for _, fn := range m.funcs {
+		for _, p := range fn.Params {
+			if isRestConfigPointer(p.Type()) && !covered[p] {
+				addRoot(p, m.aliases(p), false)
+			}
+		}
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				switch v := instr.(type) {
+				case *ssa.Call, *ssa.Extract, *ssa.UnOp:
+					if v := v.(ssa.Value); isRestConfigPointer(v.Type()) && !covered[v] {
+						addRoot(v, m.aliases(v), false)
+					}
+				}
+			}
+		}
+	}
+
+	goroutines := map[*ssa.Function]bool{}
+	for _, fn := range m.funcs {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				if g, ok := instr.(*ssa.Go); ok {
+					switch v := g.Call.Value.(type) {
+					case *ssa.Function:
+						goroutines[v] = true
+					case *ssa.MakeClosure:
+						goroutines[v.Fn.(*ssa.Function)] = true
+					}
+				}
+			}
+		}
+	}
+
+	reported := map[ssa.Instruction]bool{}
+	for _, root := range roots {
+		var ctors []restConfigSink
+		var writes []restConfigWrite
+		for _, v := range root.aliases {
+			for _, ref := range *v.Referrers() {
+				switch ref := ref.(type) {
+				case *ssa.FieldAddr:
+					if f := structField(deref(ref.X.Type()), ref.Field); f != nil {
+						for _, w := range fieldWrites(ref) {
+							writes = append(writes, restConfigWrite{instr: w, field: f.Name()})
+						}
+					}
+				case ssa.CallInstruction:
+					obj := calleeObject(ref.Common())
+					switch {
+					case isKubernetesClientConstructor(obj):
+						ctors = append(ctors, restConfigSink{call: ref, name: obj.Pkg().Name() + "." + obj.Name()})
+					case obj != nil && obj.Pkg() != nil && obj.Pkg().Path() == PkgClientGoRest && obj.Name() == "Wrap":
+						writes = append(writes, restConfigWrite{instr: ref, field: "WrapTransport"})
+					}
+				}
+			}
+		}
+		if len(ctors) == 0 {
+			continue
+		}
+		for _, w := range writes {
+			if reported[w.instr] {
+				continue
+			}
+			for _, c := range ctors {
+				if m.mayRunAfter(w.instr, c.call, root.home) {
+					reported[w.instr] = true
+					pass.Reportf(w.instr.Pos(), "rest.Config.%s is written after the config was passed to %s, so clients built from it may or may not see the change; give each client a rest.CopyConfig instead",
+						w.field, c.name)
+					break
+				}
+			}
+			// A config created in the goroutine is its own.
+			if !reported[w.instr] && goroutines[w.instr.Parent()] && !(root.fresh && root.home == w.instr.Parent()) {
+				reported[w.instr] = true
+				pass.Reportf(w.instr.Pos(), "rest.Config.%s is written in a goroutine on a config shared with other goroutines and clients; change a rest.CopyConfig instead",
+					w.field)
+			}
+		}
+	}
+	return nil, nil
+}
+
+func isRestConfigPointer(t types.Type) bool {
+	p, ok := t.(*types.Pointer)
+	return ok && isNamed(p.Elem(), PkgClientGoRest, "Config")
+}
+
+// fieldWrites returns the stores to addr, or to the fields and elements
+// within it, as for cfg.Impersonate.UserName = "x".
+func fieldWrites(addr ssa.Value) []ssa.Instruction {
+	var out []ssa.Instruction
+	for _, ref := range *addr.Referrers() {
+		switch ref := ref.(type) {
+		case *ssa.Store:
+			if ref.Addr == addr {
+				out = append(out, ref)
+			}
+		case *ssa.FieldAddr:
+			out = append(out, fieldWrites(ref)...)
+		case *ssa.IndexAddr:
+			out = append(out, fieldWrites(ref)...)
+		}
+	}
+	return out
+}
+
+// mayRunAfter reports whether instr may execute after ref: later in the same
+// function, or in a function called, or a closure created, after a call that
+// leads to ref. Calls are not followed out of home, where the config comes
+// from, as callers of home may get a different config each time.
+func (m *restConfigModel) mayRunAfter(instr, ref ssa.Instruction, home *ssa.Function) bool {
+	if instr.Parent() == ref.Parent() {
+		return instr != ref && executesBefore(ref, instr)
+	}
+	refs := m.callSites(ref, home)
+	for _, i := range m.callSites(instr, home) {
+		for _, r := range refs {
+			if i.Parent() == r.Parent() && r != i && executesBefore(r, i) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// callSites returns instr and the calls and closures in the package that
+// lead to its function, up to home.
+func (m *restConfigModel) callSites(instr ssa.Instruction, home *ssa.Function) []ssa.Instruction {
+	out := []ssa.Instruction{instr}
+	seen := map[*ssa.Function]bool{}
+	for i := 0; i < len(out) && i <= maxTraceDepth; i++ {
+		fn := out[i].Parent()
+		if seen[fn] || fn == home {
+			continue
+		}
+		seen[fn] = true
+		out = append(out, m.sites[fn]...)
+	}
+	return out
+}
@@ -0,0 +1,124 @@
+package analyzers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/amisstea/k8s-client-audit/internal/analyzers/testutil"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+const restConfigMutationImports = `package p
+
+import (
+	"net/http"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+var (
+	_ http.RoundTripper
+	_ = kubernetes.NewForConfig
+	_ = ctrl.GetConfigOrDie
+	_ = client.New
+	_ manager.Manager
+)
+`
+
+func runRestConfigMutationOnSrc(t *testing.T, src string) []analysis.Diagnostic {
+	t.Helper()
+	res, err := testutil.RunAnalyzerOnStubbedSrc(AnalyzerRestConfigMutation, restConfigMutationImports+src)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	return res.Diagnostics
+}
+
+func TestRestConfigMutation_WriteAfterConstructor_Flagged(t *testing.T) {
+	diags := runRestConfigMutationOnSrc(t, `
+func setup(mgr manager.Manager) {
+	cfg, _ := rest.InClusterConfig()
+	cs, _ := kubernetes.NewForConfig(cfg)
+	_ = cs
+	cfg.QPS = 100
+	impersonate(cfg)
+
+	shared := mgr.GetConfig()
+	newClient(shared)
+	shared.Wrap(func(rt http.RoundTripper) http.RoundTripper { return rt })
+}
+
+func impersonate(cfg *rest.Config) { cfg.Impersonate.UserName = "system:admin" }
+
+func newClient(cfg *rest.Config) client.Client {
+	c, _ := client.New(cfg, client.Options{})
+	return c
+}`)
+	if len(diags) != 3 {
+		t.Fatalf("expected 3 diagnostics, got %d: %v", len(diags), diags)
+	}
+	for i, want := range []string{"rest.Config.QPS is written after the config was passed to kubernetes.NewForConfig", "rest.Config.Impersonate", "rest.Config.WrapTransport is written after the config was passed to client.New"} {
+		if !strings.Contains(diags[i].Message, want) {
+			t.Errorf("diagnostic %d: expected %q in %q", i, want, diags[i].Message)
+		}
+	}
+}
+
+func TestRestConfigMutation_SharedAcrossGoroutines_Flagged(t *testing.T) {
+	diags := runRestConfigMutationOnSrc(t, `
+func perUser(users []string) {
+	cfg := ctrl.GetConfigOrDie()
+	for _, u := range users {
+		go func(u string) {
+			cfg.Impersonate = rest.ImpersonationConfig{UserName: u}
+			_, _ = client.New(cfg, client.Options{})
+		}(u)
+	}
+}`)
+	if len(diags) != 1 || !strings.Contains(diags[0].Message, "written in a goroutine") {
+		t.Fatalf("expected 1 goroutine diagnostic, got %v", diags)
+	}
+}
+
+func TestRestConfigMutation_CopiesAndSetupBefore_NoDiag(t *testing.T) {
+	diags := runRestConfigMutationOnSrc(t, `
+func build(users []string) {
+	cfg := ctrl.GetConfigOrDie()
+	cfg.QPS = 50
+	cfg.Burst = 100
+	_, _ = kubernetes.NewForConfig(cfg)
+
+	for _, u := range users {
+		go func(u string) {
+			c := rest.CopyConfig(cfg)
+			c.Impersonate = rest.ImpersonationConfig{UserName: u}
+			_, _ = client.New(c, client.Options{})
+		}(u)
+	}
+
+	cp := rest.CopyConfig(cfg)
+	cp.UserAgent = "other"
+	_, _ = kubernetes.NewForConfig(cp)
+}
+
+// Each call creates its own config.
+func newClientset(ua string) *kubernetes.Clientset {
+	cfg, _ := rest.InClusterConfig()
+	cfg.UserAgent = ua
+	cs, _ := kubernetes.NewForConfig(cfg)
+	return cs
+}
+
+func clients() {
+	_ = newClientset("a")
+	_ = newClientset("b")
+}`)
+	if len(diags) != 0 {
+		t.Fatalf("expected 0 diagnostics, got %v", diags)
+	}
+}
@@ -0,0 +1,430 @@
+package analyzers
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+	"golang.org/x/tools/go/ssa"
+)
+
+// restConfigFlow is the lifetime of a *rest.Config value in a package: where
+// it comes from, the fields set on it and the client constructors it reaches.
+type restConfigFlow struct {
+	// origin describes the source, like "rest.InClusterConfig".
+	origin string
+	// preset lists the fields the source sets, like the QPS and Burst
+	// controller-runtime's GetConfig defaults.
+	preset map[string]bool
+	// stores holds the stores of a non-zero value to each field.
+	stores map[string][]ssa.Instruction
+	// parent and copied are the config a copy is made of and the copy.
+	parent *restConfigFlow
+	copied ssa.Instruction
+	sinks  []restConfigSink
+
+	source  ssa.Value
+	copyOf  ssa.Value
+	aliases []ssa.Value
+}
+
+// restConfigSink is a client constructor call a config reaches.
+type restConfigSink struct {
+	call ssa.CallInstruction
+	name string
+}
+
+// restConfigModel indexes the package for tracing configs between functions.
+type restConfigModel struct {
+	pass    *analysis.Pass
+	consts  *constTracer
+	funcs   []*ssa.Function
+	flows   []*restConfigFlow
+	calls   map[*ssa.Function][]*ssa.Call
+	sites   map[*ssa.Function][]ssa.Instruction
+	globals map[*ssa.Global][]ssa.Value
+	fields  map[*types.Var][]ssa.Value
+}
+
+// restConfigFlows returns the flows of the configs created in the package:
+// rest.Config literals and variables, configs from rest.InClusterConfig,
+// clientcmd loaders and controller-runtime's GetConfig, and copies of these
+// with rest.CopyConfig or a dereference. A config is followed through
+// variables, struct fields, closures, and the parameters and results of the
+// functions of the package; configs that come from elsewhere are not
+// modeled.
+func restConfigFlows(pass *analysis.Pass, consts *constTracer) []*restConfigFlow {
+	return newRestConfigModel(pass, consts).flows
+}
+
+func newRestConfigModel(pass *analysis.Pass, consts *constTracer) *restConfigModel {
+	ssaInfo := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+	funcs := ssaInfo.SrcFuncs
+	if init := ssaInfo.Pkg.Func("init"); init != nil {
+		funcs = append(funcs[:len(funcs):len(funcs)], init)
+	}
+	m := &restConfigModel{
+		pass:    pass,
+		consts:  consts,
+		funcs:   funcs,
+		calls:   map[*ssa.Function][]*ssa.Call{},
+		sites:   map[*ssa.Function][]ssa.Instruction{},
+		globals: map[*ssa.Global][]ssa.Value{},
+		fields:  map[*types.Var][]ssa.Value{},
+	}
+
+	var flows []*restConfigFlow
+	for _, fn := range funcs {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				switch instr := instr.(type) {
+				case *ssa.Alloc:
+					if f := m.allocFlow(instr); f != nil {
+						flows = append(flows, f)
+					}
+				case *ssa.UnOp:
+					if instr.Op != token.MUL {
+						continue
+					}
+					switch addr := instr.X.(type) {
+					case *ssa.Global:
+						m.globals[addr] = append(m.globals[addr], instr)
+					case *ssa.FieldAddr:
+						f := structField(deref(addr.X.Type()), addr.Field)
+						m.fields[f] = append(m.fields[f], instr)
+					}
+				case *ssa.MakeClosure:
+					fn := instr.Fn.(*ssa.Function)
+					m.sites[fn] = append(m.sites[fn], instr)
+				case ssa.CallInstruction:
+					callee := instr.Common().StaticCallee()
+					if callee != nil {
+						m.sites[callee] = append(m.sites[callee], instr)
+					}
+					call, ok := instr.(*ssa.Call)
+					if !ok {
+						continue
+					}
+					if callee != nil {
+						m.calls[callee] = append(m.calls[callee], call)
+					}
+					if f := m.callFlow(call); f != nil {
+						flows = append(flows, f)
+					}
+				}
+			}
+		}
+	}
+
+	for _, f := range flows {
+		f.aliases = m.aliases(f.source)
+	}
+	for _, f := range flows {
+		if f.copyOf != nil {
+			for _, p := range flows {
+				if p != f && slices.Contains(p.aliases, f.copyOf) {
+					f.parent = p
+				}
+			}
+			if f.parent == nil {
+				continue
+			}
+		}
+		m.collect(f)
+		m.flows = append(m.flows, f)
+	}
+	return m
+}
+
+// allocFlow returns the flow of a rest.Config literal or variable, or nil.
+// Variables assigned a whole config are copies of it, or not modeled if it is
+// not a dereferenced config.
+func (m *restConfigModel) allocFlow(alloc *ssa.Alloc) *restConfigFlow {
+	if !isNamed(deref(alloc.Type()), PkgClientGoRest, "Config") {
+		return nil
+	}
+	pos := m.pass.Fset.Position(alloc.Pos())
+	f := &restConfigFlow{
+		origin: fmt.Sprintf("%s:%d", filepath.Base(pos.Filename), pos.Line),
+		source: alloc,
+	}
+	for _, ref := range *alloc.Referrers() {
+		st, ok := ref.(*ssa.Store)
+		if !ok || st.Addr != alloc {
+			continue
+		}
+		if _, ok := st.Val.(*ssa.Const); ok {
+			continue
+		}
+		load, ok := st.Val.(*ssa.UnOp)
+		if !ok || load.Op != token.MUL || f.copyOf != nil {
+			return nil
+		}
+		f.origin = "a copy at " + f.origin
+		f.copyOf, f.copied = load.X, st
+	}
+	return f
+}
+
+// callFlow returns the flow of the config returned by call, or nil if call
+// does not create or copy a config.
+func (m *restConfigModel) callFlow(call *ssa.Call) *restConfigFlow {
+	obj := calleeObject(&call.Call)
+	if obj == nil || obj.Pkg() == nil {
+		return nil
+	}
+	f := &restConfigFlow{origin: obj.Pkg().Name() + "." + obj.Name(), source: call}
+	switch obj.Pkg().Path() {
+	case PkgClientGoRest:
+		switch obj.Name() {
+		case "InClusterConfig":
+		case "CopyConfig":
+			f.copyOf, f.copied = call.Call.Args[0], call
+		default:
+			return nil
+		}
+	case PkgClientGoClientcmd:
+	case PkgControllerRuntimeConfig, PkgControllerRuntime:
+		if !strings.HasPrefix(obj.Name(), "GetConfig") {
+			return nil
+		}
+		// controller-runtime defaults QPS to 20 and Burst to 30.
+		f.preset = map[string]bool{"QPS": true, "Burst": true}
+	default:
+		return nil
+	}
+	switch t := call.Type().(type) {
+	case *types.Tuple:
+		if t.Len() == 0 || !isNamed(deref(t.At(0).Type()), PkgClientGoRest, "Config") {
+			return nil
+		}
+		f.source = nil
+		for _, ref := range *call.Referrers() {
+			if ex, ok := ref.(*ssa.Extract); ok && ex.Index == 0 {
+				f.source = ex
+			}
+		}
+		if f.source == nil {
+			return nil
+		}
+	default:
+		if !isNamed(deref(t), PkgClientGoRest, "Config") {
+			return nil
+		}
+	}
+	return f
+}
+
+// calleeObject returns the function or package-level function variable
+// called, like ctrl.GetConfigOrDie.
+func calleeObject(common *ssa.CallCommon) types.Object {
+	if fn := ssaCallee(common); fn != nil {
+		return fn
+	}
+	if load, ok := common.Value.(*ssa.UnOp); ok && load.Op == token.MUL {
+		if g, ok := load.X.(*ssa.Global); ok {
+			return g.Object()
+		}
+	}
+	return nil
+}
+
+// aliases returns the values src flows into unchanged, in source order.
+func (m *restConfigModel) aliases(src ssa.Value) []ssa.Value {
+	seen := map[ssa.Value]bool{}
+	var out []ssa.Value
+	stack := []ssa.Value{src}
+	for len(stack) > 0 {
+		v := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[v] || v.Referrers() == nil {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+		for _, ref := range *v.Referrers() {
+			switch ref := ref.(type) {
+			case *ssa.Phi:
+				stack = append(stack, ref)
+			case *ssa.MakeInterface:
+				stack = append(stack, ref)
+			case *ssa.Store:
+				// The config is kept in a variable or a struct field.
+				if ref.Val != v {
+					continue
+				}
+				switch addr := ref.Addr.(type) {
+				case *ssa.Alloc:
+					stack = append(stack, variableLoads(addr)...)
+				case *ssa.Global:
+					stack = append(stack, m.globals[addr]...)
+				case *ssa.FieldAddr:
+					stack = append(stack, m.fields[structField(deref(addr.X.Type()), addr.Field)]...)
+				}
+			case *ssa.MakeClosure:
+				for i, b := range ref.Bindings {
+					if b == v {
+						stack = append(stack, ref.Fn.(*ssa.Function).FreeVars[i])
+					}
+				}
+			case *ssa.Return:
+				for i, r := range ref.Results {
+					if r != v {
+						continue
+					}
+					for _, call := range m.calls[ref.Parent()] {
+						if len(ref.Results) == 1 {
+							stack = append(stack, call)
+							continue
+						}
+						for _, ex := range *call.Referrers() {
+							if ex, ok := ex.(*ssa.Extract); ok && ex.Index == i {
+								stack = append(stack, ex)
+							}
+						}
+					}
+				}
+			case ssa.CallInstruction:
+				callee := ref.Common().StaticCallee()
+				if callee == nil || callee.Blocks == nil {
+					continue
+				}
+				for i, arg := range ref.Common().Args {
+					if arg == v && i < len(callee.Params) {
+						stack = append(stack, callee.Params[i])
+					}
+				}
+			}
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Pos() < out[j].Pos() })
+	return out
+}
+
+// variableLoads returns the loads of the local variable at addr, including
+// those of closures capturing it.
+func variableLoads(addr ssa.Value) []ssa.Value {
+	var out []ssa.Value
+	for _, ref := range *addr.Referrers() {
+		switch ref := ref.(type) {
+		case *ssa.UnOp:
+			if ref.Op == token.MUL {
+				out = append(out, ref)
+			}
+		case *ssa.MakeClosure:
+			for i, b := range ref.Bindings {
+				if b == addr {
+					out = append(out, variableLoads(ref.Fn.(*ssa.Function).FreeVars[i])...)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// collect records the field stores and client constructor calls of the
+// aliases of f.
+func (m *restConfigModel) collect(f *restConfigFlow) {
+	f.stores = map[string][]ssa.Instruction{}
+	for _, v := range f.aliases {
+		for _, ref := range *v.Referrers() {
+			switch ref := ref.(type) {
+			case *ssa.FieldAddr:
+				field := structField(deref(ref.X.Type()), ref.Field)
+				if field == nil {
+					continue
+				}
+				for _, st := range *ref.Referrers() {
+					st, ok := st.(*ssa.Store)
+					if !ok || st.Addr != ref {
+						continue
+					}
+					if c := m.consts.value(st.Val, 0); c != nil && isZeroConstant(c) {
+						continue
+					}
+					f.stores[field.Name()] = append(f.stores[field.Name()], st)
+				}
+			case ssa.CallInstruction:
+				common := ref.Common()
+				if callee := common.StaticCallee(); callee != nil && callee.Blocks != nil {
+					continue
+				}
+				if name := clientConstructorName(common); name != "" {
+					f.sinks = append(f.sinks, restConfigSink{call: ref, name: name})
+				}
+			}
+		}
+	}
+}
+
+// clientConstructorName returns the name of the function called by common,
+// like "kubernetes.NewForConfig", if it builds a client from a config.
+func clientConstructorName(common *ssa.CallCommon) string {
+	obj := calleeObject(common)
+	if !isKubernetesClientConstructor(obj) && !isManagerConstructor(obj) {
+		return ""
+	}
+	return obj.Pkg().Name() + "." + obj.Name()
+}
+
+// unset returns the fields that are still zero when the config reaches at.
+func (f *restConfigFlow) unset(at ssa.Instruction, fields ...string) []string {
+	var out []string
+	for _, field := range fields {
+		if !f.isSet(field, at) {
+			out = append(out, field)
+		}
+	}
+	return out
+}
+
+func (f *restConfigFlow) isSet(field string, at ssa.Instruction) bool {
+	if f.preset[field] {
+		return true
+	}
+	for _, st := range f.stores[field] {
+		if executesBefore(st, at) {
+			return true
+		}
+	}
+	return f.parent != nil && f.parent.isSet(field, f.copied)
+}
+
+// executesBefore reports whether a may execute before b. Instructions of
+// other functions, like helpers configuring a config, are assumed to.
+func executesBefore(a, b ssa.Instruction) bool {
+	if a.Parent() != b.Parent() {
+		return true
+	}
+	if a.Block() != b.Block() {
+		return reaches(a.Block(), b.Block())
+	}
+	for _, instr := range a.Block().Instrs {
+		switch instr {
+		case a:
+			return true
+		case b:
+			return false
+		}
+	}
+	return false
+}
+
+// reportUnsetRestConfigFields reports the client constructors that configs
+// reach with some of fields still zero.
+func reportUnsetRestConfigFields(pass *analysis.Pass, flows []*restConfigFlow, advice string, fields ...string) {
+	for _, f := range flows {
+		for _, sink := range f.sinks {
+			if unset := f.unset(sink.call, fields...); len(unset) > 0 {
+				pass.Reportf(sink.call.Pos(), "rest.Config from %s reaches %s with default %s; %s",
+					f.origin, sink.name, strings.Join(unset, " and "), advice)
+			}
+		}
+	}
+}
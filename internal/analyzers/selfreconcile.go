@@ -0,0 +1,191 @@
+package analyzers
+
+import (
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+	"golang.org/x/tools/go/ssa"
+)
+
+// AnalyzerSelfReconcile flags controllers that write a fresh timestamp to the
+// status of the resource they watch without a predicate ignoring status-only
+// updates.
+//
+// Controllers are found from controller-runtime builder chains ending in
+// Complete, and correlated with the reconciler passed to it: the methods of
+// its type and the functions of the package they call. When these call
+// metav1.Now and update or patch the status of a type registered with For or
+// Watches, every status write changes the object and triggers another
+// reconcile. Watches filtered by a predicate, through builder.WithPredicates
+// or WithEventFilter, are not reported unless the only predicate is
+// ResourceVersionChangedPredicate, which lets status updates through.
+var AnalyzerSelfReconcile = &analysis.Analyzer{
+	Name:     "selfreconcile",
+	Doc:      "flags controllers writing metav1.Now() to the status of a watched resource without a predicate such as GenerationChangedPredicate",
+	Run:      runSelfReconcile,
+	Requires: []*analysis.Analyzer{buildssa.Analyzer},
+}
+
+// builderWatch is a For, Owns or Watches registration of a controller
+// builder.
+type builderWatch struct {
+	call     ssa.CallInstruction
+	method   string
+	object   types.Type
+	filtered bool
+}
+
+func runSelfReconcile(pass *analysis.Pass) (any, error) {
+	ssaInfo := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+
+	methods := map[*types.TypeName][]*ssa.Function{}
+	for _, fn := range ssaInfo.SrcFuncs {
+		if recv := fn.Signature.Recv(); recv != nil {
+			if n, ok := deref(recv.Type()).(*types.Named); ok {
+				methods[n.Obj()] = append(methods[n.Obj()], fn)
+			}
+		}
+	}
+
+	for _, fn := range ssaInfo.SrcFuncs {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				ci, ok := instr.(ssa.CallInstruction)
+				if !ok {
+					continue
+				}
+				common := ci.Common()
+				callee := ssaCallee(common)
+				args := ssaArgs(common)
+				if !isBuilderMethod(callee, "Complete") || len(args) == 0 {
+					continue
+				}
+				reconciler, ok := deref(objectRoot(args[0]).Type()).(*types.Named)
+				if !ok {
+					continue
+				}
+				watches, filtered := builderWatches(ssaReceiver(common))
+				if filtered {
+					continue
+				}
+				updated, stamped := statusWrites(reachableFuncs(methods[reconciler.Obj()]))
+				if !stamped {
+					continue
+				}
+				for _, w := range watches {
+					// builderWatches also returns Owns registrations, for
+					// broadwatch.
+					if w.filtered || w.method == "Owns" {
+						continue
+					}
+					for _, t := range updated {
+						if types.Identical(t, w.object) {
+							pass.Reportf(w.call.Pos(), "%s writes metav1.Now() to the status of the %s it watches, so every status update triggers another reconcile; add builder.WithPredicates(predicate.GenerationChangedPredicate{})",
+								reconciler.Obj().Name(), types.TypeString(w.object, (*types.Package).Name))
+							break
+						}
+					}
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// isBuilderMethod reports whether fn is one of the named methods of the
+// controller-runtime builder.
+func isBuilderMethod(fn *types.Func, names ...string) bool {
+	if fn == nil || fn.Pkg() == nil || fn.Pkg().Path() != PkgControllerRuntimeBuilder {
+		return false
+	}
+	for _, name := range names {
+		if fn.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// builderWatches follows a builder chain back from recv and returns its For,
+// Owns and Watches registrations, and whether WithEventFilter sets a predicate
+// filtering status-only updates for all of them.
+func builderWatches(recv ssa.Value) ([]builderWatch, bool) {
+	var watches []builderWatch
+	for recv != nil {
+		call, ok := recv.(*ssa.Call)
+		if !ok {
+			break
+		}
+		callee := ssaCallee(&call.Call)
+		if !isBuilderMethod(callee, "For", "Owns", "Watches", "WithEventFilter", "WithOptions", "Named") {
+			break
+		}
+		args := ssaArgs(&call.Call)
+		switch callee.Name() {
+		case "For", "Owns", "Watches":
+			w := builderWatch{call: call, method: callee.Name(), object: deref(objectRoot(args[0]).Type())}
+			for _, opt := range variadicValues(args[len(args)-1]) {
+				if filtersStatusUpdates(opt) {
+					w.filtered = true
+				}
+			}
+			watches = append(watches, w)
+		case "WithEventFilter":
+			if isStatusFilteringPredicate(args[0]) {
+				return watches, true
+			}
+		}
+		recv = ssaReceiver(&call.Call)
+	}
+	return watches, false
+}
+
+// filtersStatusUpdates reports whether opt is a builder.WithPredicates option
+// with a predicate filtering status-only updates.
+func filtersStatusUpdates(opt ssa.Value) bool {
+	call, ok := objectRoot(opt).(*ssa.Call)
+	if !ok || !isBuilderMethod(ssaCallee(&call.Call), "WithPredicates") {
+		return false
+	}
+	for _, p := range variadicValues(call.Call.Args[0]) {
+		if isStatusFilteringPredicate(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// isStatusFilteringPredicate reports whether p may filter out updates that
+// only change the status. Every predicate but ResourceVersionChangedPredicate
+// is assumed to, as custom predicates usually compare the spec or generation.
+func isStatusFilteringPredicate(p ssa.Value) bool {
+	return !isNamed(deref(objectRoot(p).Type()), PkgControllerRuntimePredicate, "ResourceVersionChangedPredicate")
+}
+
+// statusWrites returns the types of the objects whose status is updated or
+// patched by funcs, and whether funcs call metav1.Now.
+func statusWrites(funcs map[*ssa.Function]bool) (objects []types.Type, stamped bool) {
+	for fn := range funcs {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				ci, ok := instr.(ssa.CallInstruction)
+				if !ok {
+					continue
+				}
+				common := ci.Common()
+				callee := ssaCallee(common)
+				switch {
+				case callee == nil || callee.Pkg() == nil:
+				case callee.Pkg().Path() == PkgMetaV1 && callee.Name() == "Now":
+					stamped = true
+				case (callee.Name() == "Update" || callee.Name() == "Patch") && isStatusWriter(ssaReceiver(common)):
+					if args := ssaArgs(common); len(args) >= 2 {
+						objects = append(objects, deref(objectRoot(args[1]).Type()))
+					}
+				}
+			}
+		}
+	}
+	return objects, stamped
+}
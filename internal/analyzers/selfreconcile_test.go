@@ -0,0 +1,152 @@
+package analyzers
+
+import (
+	"testing"
+
+	"github.com/amisstea/k8s-client-audit/internal/analyzers/testutil"
+)
+
+const selfReconcileImports = `package p
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+var (
+	_ = context.TODO
+	_ = metav1.Now
+	_ = builder.WithPredicates
+	_ client.Client
+	_ handler.EventHandler
+	_ predicate.Predicate
+)
+
+type WidgetStatus struct{ LastSynced metav1.Time }
+
+type Widget struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+	Status WidgetStatus
+}
+
+func (w *Widget) DeepCopyObject() runtime.Object { c := *w; return &c }
+
+type Reconciler struct{ c client.Client }
+
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var w Widget
+	if err := r.c.Get(ctx, req.NamespacedName, &w); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	w.Status.LastSynced = metav1.Now()
+	return ctrl.Result{}, r.c.Status().Update(ctx, &w)
+}
+`
+
+func runSelfReconcileOnSrc(t *testing.T, src string) int {
+	t.Helper()
+	res, err := testutil.RunAnalyzerOnStubbedSrc(AnalyzerSelfReconcile, src)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	return len(res.Diagnostics)
+}
+
+func TestSelfReconcile_UnfilteredWatch_Flagged(t *testing.T) {
+	src := selfReconcileImports + `
+func (r *Reconciler) SetupWithManager(mgr manager.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).For(&Widget{}).Complete(r)
+}
+
+func (r *Reconciler) SetupVersioned(mgr manager.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&Widget{}, builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})).
+		Complete(r)
+}`
+	if n := runSelfReconcileOnSrc(t, src); n != 2 {
+		t.Fatalf("expected 2 diagnostics, got %d", n)
+	}
+}
+
+func TestSelfReconcile_HelperAndWatches_Flagged(t *testing.T) {
+	src := selfReconcileImports + `
+type Syncer struct{ c client.Client }
+
+func (s *Syncer) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var w Widget
+	if err := s.c.Get(ctx, req.NamespacedName, &w); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	return ctrl.Result{}, stamp(ctx, s.c, &w)
+}
+
+func stamp(ctx context.Context, c client.Client, w *Widget) error {
+	orig := w.DeepCopyObject().(*Widget)
+	w.Status.LastSynced = metav1.Now()
+	return c.Status().Patch(ctx, w, client.MergeFrom(orig))
+}
+
+func (s *Syncer) SetupWithManager(mgr manager.Manager) error {
+	b := ctrl.NewControllerManagedBy(mgr)
+	b = b.Watches(&Widget{}, &handler.EnqueueRequestForObject{})
+	return b.Complete(s)
+}`
+	if n := runSelfReconcileOnSrc(t, src); n != 1 {
+		t.Fatalf("expected 1 diagnostic, got %d", n)
+	}
+}
+
+func TestSelfReconcile_Predicates_NoDiag(t *testing.T) {
+	src := selfReconcileImports + `
+func (r *Reconciler) SetupWithManager(mgr manager.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&Widget{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
+
+func (r *Reconciler) SetupFiltered(mgr manager.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&Widget{}).
+		WithEventFilter(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.LabelChangedPredicate{})).
+		Complete(r)
+}
+
+type Counter struct{ c client.Client }
+
+func (c *Counter) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var w Widget
+	if err := c.c.Get(ctx, req.NamespacedName, &w); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	return ctrl.Result{}, c.c.Status().Update(ctx, &w)
+}
+
+func (c *Counter) SetupWithManager(mgr manager.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).For(&Widget{}).Complete(c)
+}`
+	if n := runSelfReconcileOnSrc(t, src); n != 0 {
+		t.Fatalf("expected 0 diagnostics, got %d", n)
+	}
+}
+
+func TestSelfReconcile_Owns_NoDiag(t *testing.T) {
+	src := selfReconcileImports + `
+func (r *Reconciler) SetupWithManager(mgr manager.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&Widget{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Owns(&Widget{}).
+		Complete(r)
+}`
+	if n := runSelfReconcileOnSrc(t, src); n != 0 {
+		t.Fatalf("expected 0 diagnostics, got %d", n)
+	}
+}
@@ -0,0 +1,339 @@
+package testutil
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	pathpkg "path"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/amisstea/k8s-client-audit/internal/fix"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Package is a type-checked test package.
+type Package struct {
+	Path  string
+	Files []*ast.File
+	Types *types.Package
+	Info  *types.Info
+}
+
+// Result is the outcome of running an analyzer over stubbed packages.
+type Result struct {
+	Fset        *token.FileSet
+	Packages    map[string]*Package
+	Diagnostics []analysis.Diagnostic
+
+	sources map[string][]byte // by file name
+}
+
+// RunAnalyzerOnStubbedSrc type-checks src as package "p" against the stub
+// Kubernetes packages in Stubs and runs an, including its requirements.
+func RunAnalyzerOnStubbedSrc(an *analysis.Analyzer, src string) (*Result, error) {
+	return RunAnalyzerOnPackages(an, map[string]string{"p": src})
+}
+
+// RunAnalyzerOnPackages type-checks the given packages, keyed by import path,
+// against each other, the stubs and the standard library. It runs an on every
+// one of them in dependency order, so facts exported for a package are
+// visible to its importers, and returns the diagnostics of all packages.
+func RunAnalyzerOnPackages(an *analysis.Analyzer, srcs map[string]string) (*Result, error) {
+	return runAnalyzer(an, newLoader(), srcs)
+}
+
+// RunAnalyzerOnFiles is like RunAnalyzerOnPackages, but takes the source of
+// each package keyed by file name, like "example.com/e2e/e2e_test.go", for
+// tests that depend on it. Each package has a single file.
+func RunAnalyzerOnFiles(an *analysis.Analyzer, files map[string]string) (*Result, error) {
+	l := newLoader()
+	srcs := map[string]string{}
+	for name, src := range files {
+		path := pathpkg.Dir(name)
+		if _, ok := srcs[path]; ok {
+			return nil, fmt.Errorf("several files for package %s", path)
+		}
+		srcs[path] = src
+		l.names[path] = name
+	}
+	return runAnalyzer(an, l, srcs)
+}
+
+func runAnalyzer(an *analysis.Analyzer, l *loader, srcs map[string]string) (*Result, error) {
+	paths := make([]string, 0, len(srcs))
+	for path, src := range srcs {
+		l.srcs[path] = src
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if _, err := l.load(path); err != nil {
+			return nil, err
+		}
+	}
+
+	r := &runner{facts: map[factKey]analysis.Fact{}, results: map[actionKey]any{}}
+	res := &Result{Fset: l.fset, Packages: map[string]*Package{}, sources: l.sources}
+	for _, pkg := range l.order {
+		if _, ok := srcs[pkg.Path]; !ok {
+			continue
+		}
+		res.Packages[pkg.Path] = pkg
+		if _, err := r.run(an, pkg, l.fset, func(d analysis.Diagnostic) {
+			res.Diagnostics = append(res.Diagnostics, d)
+		}); err != nil {
+			return nil, fmt.Errorf("%s: %w", pkg.Path, err)
+		}
+	}
+	return res, nil
+}
+
+// ApplyFixes applies the first suggested fix of every diagnostic and returns
+// the new source of each rewritten package, keyed by import path.
+func (r *Result) ApplyFixes() (map[string]string, error) {
+	out, err := fix.Apply(r.Fset, r.Diagnostics, func(name string) ([]byte, error) {
+		if src, ok := r.sources[name]; ok {
+			return src, nil
+		}
+		return nil, fmt.Errorf("unknown file %s", name)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(out.Skipped) > 0 {
+		return nil, fmt.Errorf("conflicting fixes: %v", out.Skipped)
+	}
+	fixed := map[string]string{}
+	for path, pkg := range r.Packages {
+		for _, f := range pkg.Files {
+			if src, ok := out.Files[r.Fset.File(f.Pos()).Name()]; ok {
+				fixed[path] = string(src)
+			}
+		}
+	}
+	return fixed, nil
+}
+
+// MustApplyFixes applies the fixes of res, checks that the rewritten
+// packages still type-check, and returns their sources.
+func MustApplyFixes(tb testing.TB, res *Result) map[string]string {
+	tb.Helper()
+	fixed, err := res.ApplyFixes()
+	if err != nil {
+		tb.Fatalf("apply fixes: %v", err)
+	}
+	all := map[string]string{}
+	for path, pkg := range res.Packages {
+		all[path] = string(res.sources[res.Fset.File(pkg.Files[0].Pos()).Name()])
+	}
+	for path, src := range fixed {
+		all[path] = src
+	}
+	if err := TypeCheck(all); err != nil {
+		tb.Fatalf("fixed source does not compile: %v\n%s", err, fixed)
+	}
+	return fixed
+}
+
+// TypeCheck reports the first type error in the given packages, keyed by
+// import path, when checked against the stubs and the standard library.
+func TypeCheck(srcs map[string]string) error {
+	l := newLoader()
+	for path, src := range srcs {
+		l.srcs[path] = src
+	}
+	for path := range srcs {
+		if _, err := l.load(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loader type-checks test packages and stubs from source, and the standard
+// library from export data.
+type loader struct {
+	fset    *token.FileSet
+	srcs    map[string]string
+	names   map[string]string // file names of srcs, by import path
+	pkgs    map[string]*Package
+	order   []*Package // dependencies first
+	std     types.Importer
+	sources map[string][]byte
+}
+
+func newLoader() *loader {
+	return &loader{
+		fset:    token.NewFileSet(),
+		srcs:    map[string]string{},
+		names:   map[string]string{},
+		pkgs:    map[string]*Package{},
+		std:     importer.Default(),
+		sources: map[string][]byte{},
+	}
+}
+
+func (l *loader) Import(path string) (*types.Package, error) {
+	if _, ok := l.srcs[path]; !ok {
+		if _, ok := Stubs[path]; !ok {
+			return l.std.Import(path)
+		}
+	}
+	pkg, err := l.load(path)
+	if err != nil {
+		return nil, err
+	}
+	return pkg.Types, nil
+}
+
+func (l *loader) load(path string) (*Package, error) {
+	if pkg, ok := l.pkgs[path]; ok {
+		if pkg == nil {
+			return nil, fmt.Errorf("import cycle through %s", path)
+		}
+		return pkg, nil
+	}
+	src, ok := l.srcs[path]
+	if !ok {
+		src = Stubs[path]
+	}
+	l.pkgs[path] = nil
+
+	name, ok := l.names[path]
+	if !ok {
+		name = path + "/" + lastSegment(path) + ".go"
+	}
+	f, err := parser.ParseFile(l.fset, name, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	l.sources[name] = []byte(src)
+	// Load imports first so that l.order lists dependencies first.
+	for _, spec := range f.Imports {
+		if ipath, err := strconv.Unquote(spec.Path.Value); err == nil {
+			if _, err := l.Import(ipath); err != nil {
+				return nil, err
+			}
+		}
+	}
+	info := &types.Info{
+		Types:        map[ast.Expr]types.TypeAndValue{},
+		Instances:    map[*ast.Ident]types.Instance{},
+		Defs:         map[*ast.Ident]types.Object{},
+		Uses:         map[*ast.Ident]types.Object{},
+		Implicits:    map[ast.Node]types.Object{},
+		Selections:   map[*ast.SelectorExpr]*types.Selection{},
+		Scopes:       map[ast.Node]*types.Scope{},
+		FileVersions: map[*ast.File]string{},
+	}
+	conf := types.Config{Importer: l}
+	tpkg, err := conf.Check(path, l.fset, []*ast.File{f}, info)
+	if err != nil {
+		return nil, err
+	}
+	pkg := &Package{Path: path, Files: []*ast.File{f}, Types: tpkg, Info: info}
+	l.pkgs[path] = pkg
+	l.order = append(l.order, pkg)
+	return pkg, nil
+}
+
+type actionKey struct {
+	an  *analysis.Analyzer
+	pkg *Package
+}
+
+type factKey struct {
+	an  *analysis.Analyzer
+	obj types.Object   // nil for package facts
+	pkg *types.Package // set for package facts
+	typ reflect.Type
+}
+
+// runner runs analyzers and their requirements, sharing facts between
+// packages.
+type runner struct {
+	facts   map[factKey]analysis.Fact
+	results map[actionKey]any
+}
+
+func (r *runner) run(an *analysis.Analyzer, pkg *Package, fset *token.FileSet, report func(analysis.Diagnostic)) (any, error) {
+	key := actionKey{an, pkg}
+	if res, ok := r.results[key]; ok {
+		return res, nil
+	}
+	resultOf := map[*analysis.Analyzer]any{}
+	for _, req := range an.Requires {
+		res, err := r.run(req, pkg, fset, func(analysis.Diagnostic) {})
+		if err != nil {
+			return nil, err
+		}
+		resultOf[req] = res
+	}
+
+	pass := &analysis.Pass{
+		Analyzer:   an,
+		Fset:       fset,
+		Files:      pkg.Files,
+		Pkg:        pkg.Types,
+		TypesInfo:  pkg.Info,
+		TypesSizes: types.SizesFor("gc", "amd64"),
+		ResultOf:   resultOf,
+		Report:     report,
+		ReadFile:   func(string) ([]byte, error) { return nil, fmt.Errorf("ReadFile is not supported in tests") },
+	}
+	pass.ImportObjectFact = func(obj types.Object, fact analysis.Fact) bool {
+		return r.importFact(factKey{an: an, obj: obj, typ: reflect.TypeOf(fact)}, fact)
+	}
+	pass.ExportObjectFact = func(obj types.Object, fact analysis.Fact) {
+		r.facts[factKey{an: an, obj: obj, typ: reflect.TypeOf(fact)}] = fact
+	}
+	pass.ImportPackageFact = func(p *types.Package, fact analysis.Fact) bool {
+		return r.importFact(factKey{an: an, pkg: p, typ: reflect.TypeOf(fact)}, fact)
+	}
+	pass.ExportPackageFact = func(fact analysis.Fact) {
+		r.facts[factKey{an: an, pkg: pkg.Types, typ: reflect.TypeOf(fact)}] = fact
+	}
+	pass.AllObjectFacts = func() []analysis.ObjectFact {
+		var out []analysis.ObjectFact
+		for k, f := range r.facts {
+			if k.an == an && k.obj != nil {
+				out = append(out, analysis.ObjectFact{Object: k.obj, Fact: f})
+			}
+		}
+		return out
+	}
+	pass.AllPackageFacts = func() []analysis.PackageFact {
+		var out []analysis.PackageFact
+		for k, f := range r.facts {
+			if k.an == an && k.pkg != nil {
+				out = append(out, analysis.PackageFact{Package: k.pkg, Fact: f})
+			}
+		}
+		return out
+	}
+
+	res, err := an.Run(pass)
+	if err != nil {
+		return nil, err
+	}
+	r.results[key] = res
+	return res, nil
+}
+
+// importFact copies the stored fact for k into fact, as the analysis
+// framework does, and reports whether one was found.
+func (r *runner) importFact(k factKey, fact analysis.Fact) bool {
+	stored, ok := r.facts[k]
+	if !ok {
+		return false
+	}
+	reflect.ValueOf(fact).Elem().Set(reflect.ValueOf(stored).Elem())
+	return true
+}
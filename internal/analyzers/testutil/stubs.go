@@ -0,0 +1,685 @@
+package testutil
+
+// Stubs holds minimal sources for the Kubernetes packages analyzers look for,
+// keyed by their real import path. Unlike the spoof functions, packages
+// type-checked against the stubs are fully typed, so tests can exercise facts,
+// SSA and suggested fixes, and check that fixed sources still compile.
+//
+// Only the declarations that analyzers or tests rely on are stubbed; extend
+// them as needed.
+var Stubs = map[string]string{
+	PkgMetaV1: `package v1
+
+import "time"
+
+type ObjectMeta struct {
+	Name, Namespace, ResourceVersion string
+	Generation                       int64
+	Labels, Annotations              map[string]string
+}
+
+func (m *ObjectMeta) GetName() string { return m.Name }
+
+type TypeMeta struct{ Kind, APIVersion string }
+
+type ListMeta struct{ Continue, ResourceVersion string }
+
+type ListOptions struct {
+	LabelSelector, FieldSelector string
+	Limit                        int64
+	Continue                     string
+}
+
+type GetOptions struct{ ResourceVersion string }
+type CreateOptions struct{}
+type UpdateOptions struct{}
+type PatchOptions struct{}
+type DeleteOptions struct{}
+
+type Time struct{ time.Time }
+
+func Now() Time { return Time{time.Now()} }
+
+type Condition struct {
+	Type, Status, Reason, Message string
+	LastTransitionTime            Time
+	ObservedGeneration            int64
+}
+`,
+
+	"k8s.io/apimachinery/pkg/runtime": `package runtime
+
+type Object interface{ DeepCopyObject() Object }
+`,
+
+	"k8s.io/apimachinery/pkg/types": `package types
+
+type NamespacedName struct{ Namespace, Name string }
+
+type PatchType string
+
+const MergePatchType PatchType = "application/merge-patch+json"
+`,
+
+	"k8s.io/apimachinery/pkg/watch": `package watch
+
+type Event struct{ Object any }
+
+type Interface interface {
+	Stop()
+	ResultChan() <-chan Event
+}
+`,
+
+	"k8s.io/api/core/v1": `package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type PodSpec struct{ NodeName string }
+type PodStatus struct{ Phase string }
+
+type Pod struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+	Spec   PodSpec
+	Status PodStatus
+}
+
+func (p *Pod) DeepCopy() *Pod { c := *p; return &c }
+
+func (p *Pod) DeepCopyObject() runtime.Object { return p.DeepCopy() }
+
+type PodList struct {
+	metav1.ListMeta
+	Items []Pod
+}
+
+func (l *PodList) DeepCopyObject() runtime.Object { c := *l; return &c }
+
+type ConfigMap struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+	Data map[string]string
+}
+
+func (c *ConfigMap) DeepCopy() *ConfigMap { d := *c; return &d }
+
+func (c *ConfigMap) DeepCopyObject() runtime.Object { return c.DeepCopy() }
+
+type Event struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+	Reason, Message string
+}
+
+func (e *Event) DeepCopyObject() runtime.Object { c := *e; return &c }
+
+type Secret struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+	Data map[string][]byte
+}
+
+func (s *Secret) DeepCopy() *Secret { d := *s; return &d }
+
+func (s *Secret) DeepCopyObject() runtime.Object { return s.DeepCopy() }
+`,
+
+	PkgRest: `package rest
+
+import (
+	"net/http"
+	"time"
+)
+
+type Config struct {
+	Host          string
+	UserAgent     string
+	QPS           float32
+	Burst         int
+	Timeout       time.Duration
+	Impersonate   ImpersonationConfig
+	Transport     http.RoundTripper
+	WrapTransport func(http.RoundTripper) http.RoundTripper
+}
+
+func (c *Config) Wrap(fn func(http.RoundTripper) http.RoundTripper) { c.WrapTransport = fn }
+
+type ImpersonationConfig struct {
+	UserName string
+	Groups   []string
+}
+
+func InClusterConfig() (*Config, error) { return &Config{}, nil }
+
+func CopyConfig(c *Config) *Config { d := *c; return &d }
+
+func HTTPClientFor(c *Config) (*http.Client, error) { return &http.Client{}, nil }
+
+type RESTClient struct{}
+
+func RESTClientFor(c *Config) (*RESTClient, error) { return &RESTClient{}, nil }
+`,
+
+	"k8s.io/client-go/kubernetes/typed/core/v1": `package v1
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+type PodInterface interface {
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*corev1.Pod, error)
+	List(ctx context.Context, opts metav1.ListOptions) (*corev1.PodList, error)
+	Create(ctx context.Context, pod *corev1.Pod, opts metav1.CreateOptions) (*corev1.Pod, error)
+	Update(ctx context.Context, pod *corev1.Pod, opts metav1.UpdateOptions) (*corev1.Pod, error)
+	UpdateStatus(ctx context.Context, pod *corev1.Pod, opts metav1.UpdateOptions) (*corev1.Pod, error)
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+	Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
+}
+
+type CoreV1Interface interface {
+	Pods(namespace string) PodInterface
+}
+`,
+
+	PkgKubernetes: `package kubernetes
+
+import (
+	"net/http"
+
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+)
+
+type Interface interface {
+	CoreV1() corev1.CoreV1Interface
+}
+
+type Clientset struct{ core corev1.CoreV1Interface }
+
+func (c *Clientset) CoreV1() corev1.CoreV1Interface { return c.core }
+
+func NewForConfig(c *rest.Config) (*Clientset, error) { return &Clientset{}, nil }
+
+func NewForConfigOrDie(c *rest.Config) *Clientset { return &Clientset{} }
+
+func NewForConfigAndClient(c *rest.Config, httpClient *http.Client) (*Clientset, error) {
+	return &Clientset{}, nil
+}
+`,
+
+	PkgWorkqueue: `package workqueue
+
+import "time"
+
+type Interface interface {
+	Add(item any)
+	Get() (item any, shutdown bool)
+	Done(item any)
+	Len() int
+	ShutDown()
+}
+
+type RateLimiter interface {
+	When(item any) time.Duration
+	Forget(item any)
+	NumRequeues(item any) int
+}
+
+type RateLimitingInterface interface {
+	Interface
+	AddRateLimited(item any)
+	AddAfter(item any, d time.Duration)
+	Forget(item any)
+}
+
+type queue struct{ RateLimitingInterface }
+
+type Type struct{ queue }
+
+func New() *Type                 { return &Type{} }
+func NewNamed(name string) *Type { return &Type{} }
+
+func DefaultControllerRateLimiter() RateLimiter { return nil }
+
+func NewRateLimitingQueue(rl RateLimiter) RateLimitingInterface { return queue{} }
+
+func NewNamedRateLimitingQueue(rl RateLimiter, name string) RateLimitingInterface { return queue{} }
+`,
+
+	PkgControllerRuntime: `package client
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+type Object interface {
+	runtime.Object
+	GetName() string
+}
+
+type ObjectList interface{ runtime.Object }
+
+type ObjectKey = types.NamespacedName
+
+type ListOption interface{}
+type GetOption interface{}
+type UpdateOption interface{}
+type PatchOption interface{}
+
+type InNamespace string
+
+type MatchingLabels map[string]string
+
+type Patch interface{}
+
+func MergeFrom(obj Object) Patch { return nil }
+
+type Reader interface {
+	Get(ctx context.Context, key ObjectKey, obj Object, opts ...GetOption) error
+	List(ctx context.Context, list ObjectList, opts ...ListOption) error
+}
+
+type Writer interface {
+	Update(ctx context.Context, obj Object, opts ...UpdateOption) error
+	Patch(ctx context.Context, obj Object, patch Patch, opts ...PatchOption) error
+}
+
+type SubResourceWriter interface {
+	Update(ctx context.Context, obj Object, opts ...UpdateOption) error
+	Patch(ctx context.Context, obj Object, patch Patch, opts ...PatchOption) error
+}
+
+type Client interface {
+	Reader
+	Writer
+	Status() SubResourceWriter
+}
+
+type Options struct{}
+
+func New(config any, options Options) (Client, error) { return nil, nil }
+
+func WithFieldOwner(c Client, fieldOwner string) Client { return c }
+
+func IgnoreNotFound(err error) error { return err }
+`,
+
+	PkgManager: `package manager
+
+import (
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type Manager interface {
+	GetClient() client.Client
+	GetAPIReader() client.Reader
+	GetCache() cache.Cache
+	GetConfig() *rest.Config
+}
+
+type Options struct{ Cache cache.Options }
+
+func New(config *rest.Config, options Options) (Manager, error) { return nil, nil }
+`,
+
+	PkgPredicate: `package predicate
+
+import "sigs.k8s.io/controller-runtime/pkg/client"
+
+type Predicate interface {
+	Update(objectOld, objectNew client.Object) bool
+}
+
+type Funcs struct {
+	UpdateFunc func(objectOld, objectNew client.Object) bool
+}
+
+func (p Funcs) Update(objectOld, objectNew client.Object) bool { return true }
+
+type GenerationChangedPredicate struct{ Funcs }
+
+type ResourceVersionChangedPredicate struct{ Funcs }
+
+type LabelChangedPredicate struct{ Funcs }
+
+type AnnotationChangedPredicate struct{ Funcs }
+
+func And(predicates ...Predicate) Predicate { return Funcs{} }
+
+func Or(predicates ...Predicate) Predicate { return Funcs{} }
+`,
+
+	"sigs.k8s.io/controller-runtime/pkg/handler": `package handler
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type EventHandler interface{ Generic() }
+
+type EnqueueRequestForObject struct{}
+
+func (e *EnqueueRequestForObject) Generic() {}
+
+type MapFunc func(ctx context.Context, obj client.Object) []reconcile.Request
+
+type funcs struct{}
+
+func (funcs) Generic() {}
+
+func EnqueueRequestsFromMapFunc(fn MapFunc) EventHandler { return funcs{} }
+`,
+
+	PkgBuilder: `package builder
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type ForOption interface{ ApplyToFor() }
+type OwnsOption interface{ ApplyToOwns() }
+type WatchesOption interface{ ApplyToWatches() }
+
+type Predicates struct{ predicates []predicate.Predicate }
+
+func (Predicates) ApplyToFor()     {}
+func (Predicates) ApplyToOwns()    {}
+func (Predicates) ApplyToWatches() {}
+
+func WithPredicates(predicates ...predicate.Predicate) Predicates {
+	return Predicates{predicates: predicates}
+}
+
+type Builder struct{}
+
+func ControllerManagedBy(m manager.Manager) *Builder { return &Builder{} }
+
+func (b *Builder) For(object client.Object, opts ...ForOption) *Builder { return b }
+func (b *Builder) Owns(object client.Object, opts ...OwnsOption) *Builder { return b }
+func (b *Builder) Watches(object client.Object, eventHandler handler.EventHandler, opts ...WatchesOption) *Builder {
+	return b
+}
+func (b *Builder) WithEventFilter(p predicate.Predicate) *Builder { return b }
+func (b *Builder) Named(name string) *Builder                     { return b }
+func (b *Builder) Complete(r reconcile.Reconciler) error          { return nil }
+`,
+
+	"sigs.k8s.io/controller-runtime": `package controllerruntime
+
+import (
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var (
+	GetConfig      = config.GetConfig
+	GetConfigOrDie = config.GetConfigOrDie
+)
+
+type Result = reconcile.Result
+
+type Request = reconcile.Request
+
+func NewControllerManagedBy(m manager.Manager) *builder.Builder { return builder.ControllerManagedBy(m) }
+
+func NewManager(config *rest.Config, options manager.Options) (manager.Manager, error) {
+	return manager.New(config, options)
+}
+`,
+
+	"sigs.k8s.io/controller-runtime/pkg/client/config": `package config
+
+import "k8s.io/client-go/rest"
+
+func GetConfig() (*rest.Config, error) { return &rest.Config{QPS: 20, Burst: 30}, nil }
+
+func GetConfigOrDie() *rest.Config { return &rest.Config{QPS: 20, Burst: 30} }
+`,
+
+	"k8s.io/client-go/tools/clientcmd": `package clientcmd
+
+import "k8s.io/client-go/rest"
+
+func BuildConfigFromFlags(masterURL, kubeconfigPath string) (*rest.Config, error) {
+	return &rest.Config{}, nil
+}
+
+func RESTConfigFromKubeConfig(configBytes []byte) (*rest.Config, error) { return &rest.Config{}, nil }
+`,
+
+	"sigs.k8s.io/controller-runtime/pkg/cache": `package cache
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type Cache interface {
+	client.Reader
+	WaitForCacheSync(ctx context.Context) bool
+}
+
+type ByObject struct{ Label labels.Selector }
+
+type Options struct {
+	DefaultLabelSelector labels.Selector
+	ByObject             map[client.Object]ByObject
+}
+`,
+
+	"k8s.io/apimachinery/pkg/labels": `package labels
+
+type Selector interface{ String() string }
+
+func Everything() Selector { return nil }
+`,
+
+	PkgCache: `package cache
+
+import "time"
+
+type Store interface {
+	List() []any
+	ListKeys() []string
+	GetByKey(key string) (item any, exists bool, err error)
+}
+
+type Indexer interface {
+	Store
+	ByIndex(indexName, indexedValue string) ([]any, error)
+}
+
+type ResourceEventHandler interface {
+	OnAdd(obj any, isInInitialList bool)
+	OnUpdate(oldObj, newObj any)
+	OnDelete(obj any)
+}
+
+type ResourceEventHandlerFuncs struct {
+	AddFunc    func(obj any)
+	UpdateFunc func(oldObj, newObj any)
+	DeleteFunc func(obj any)
+}
+
+func (r ResourceEventHandlerFuncs) OnAdd(obj any, isInInitialList bool) {}
+func (r ResourceEventHandlerFuncs) OnUpdate(oldObj, newObj any)          {}
+func (r ResourceEventHandlerFuncs) OnDelete(obj any)                     {}
+
+type ResourceEventHandlerRegistration interface{ HasSynced() bool }
+
+type SharedInformer interface {
+	AddEventHandler(handler ResourceEventHandler) (ResourceEventHandlerRegistration, error)
+	GetStore() Store
+	Run(stopCh <-chan struct{})
+	HasSynced() bool
+}
+
+type SharedIndexInformer interface {
+	SharedInformer
+	GetIndexer() Indexer
+}
+
+type InformerSynced func() bool
+
+func WaitForCacheSync(stopCh <-chan struct{}, cacheSyncs ...InformerSynced) bool { return true }
+
+func WaitForNamedCacheSync(controllerName string, stopCh <-chan struct{}, cacheSyncs ...InformerSynced) bool {
+	return true
+}
+
+func NewSharedIndexInformer(lw any, exampleObject any, defaultEventHandlerResyncPeriod time.Duration, indexers map[string]func(any) ([]string, error)) SharedIndexInformer {
+	return nil
+}
+`,
+
+	"k8s.io/client-go/listers/core/v1": `package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+type PodLister interface {
+	List(selector labels.Selector) (ret []*corev1.Pod, err error)
+	Pods(namespace string) PodNamespaceLister
+}
+
+type PodNamespaceLister interface {
+	List(selector labels.Selector) (ret []*corev1.Pod, err error)
+	Get(name string) (*corev1.Pod, error)
+}
+`,
+
+	"k8s.io/client-go/informers/core/v1": `package v1
+
+import (
+	listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+type PodInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() listers.PodLister
+}
+
+type Interface interface {
+	Pods() PodInformer
+}
+`,
+
+	"k8s.io/client-go/informers/core": `package core
+
+import v1 "k8s.io/client-go/informers/core/v1"
+
+type Interface interface{ V1() v1.Interface }
+`,
+
+	PkgInformers: `package informers
+
+import (
+	"reflect"
+	"time"
+
+	"k8s.io/client-go/informers/core"
+	"k8s.io/client-go/kubernetes"
+)
+
+type SharedInformerFactory interface {
+	Start(stopCh <-chan struct{})
+	WaitForCacheSync(stopCh <-chan struct{}) map[reflect.Type]bool
+	Shutdown()
+	Core() core.Interface
+}
+
+type SharedInformerOption func(SharedInformerFactory) SharedInformerFactory
+
+func WithNamespace(namespace string) SharedInformerOption { return nil }
+
+func NewSharedInformerFactory(client kubernetes.Interface, defaultResync time.Duration) SharedInformerFactory {
+	return nil
+}
+
+func NewSharedInformerFactoryWithOptions(client kubernetes.Interface, defaultResync time.Duration, options ...SharedInformerOption) SharedInformerFactory {
+	return nil
+}
+`,
+
+	PkgAPIErrors: `package errors
+
+func IsNotFound(err error) bool      { return false }
+func IsAlreadyExists(err error) bool { return false }
+func IsConflict(err error) bool      { return false }
+`,
+
+	"k8s.io/apimachinery/pkg/util/wait": `package wait
+
+import "time"
+
+type Backoff struct {
+	Duration time.Duration
+	Factor   float64
+	Steps    int
+}
+`,
+
+	PkgRetry: `package retry
+
+import "k8s.io/apimachinery/pkg/util/wait"
+
+var DefaultRetry = wait.Backoff{Steps: 5}
+
+var DefaultBackoff = wait.Backoff{Steps: 4}
+
+func RetryOnConflict(backoff wait.Backoff, fn func() error) error { return fn() }
+
+func OnError(backoff wait.Backoff, retriable func(error) bool, fn func() error) error { return fn() }
+`,
+
+	PkgReconcile: `package reconcile
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+type Result struct {
+	Requeue      bool
+	RequeueAfter time.Duration
+}
+
+type Request struct{ types.NamespacedName }
+
+type Reconciler interface {
+	Reconcile(ctx context.Context, req Request) (Result, error)
+}
+
+func TerminalError(err error) error { return err }
+`,
+}
@@ -6,6 +6,7 @@ import (
 	"go/token"
 	"go/types"
 	"strings"
+	"testing"
 
 	"golang.org/x/tools/go/analysis"
 	insppass "golang.org/x/tools/go/analysis/passes/inspect"
@@ -33,7 +34,7 @@ func RunAnalyzerOnSrc(an *analysis.Analyzer, src string, spoofs ...func(f *ast.F
 		Selections: map[*ast.SelectorExpr]*types.Selection{},
 	}
 	var conf types.Config
-	_, _ = conf.Check("p", fset, files, info)
+	pkg, _ := conf.Check("p", fset, files, info)
 	for _, spoof := range spoofs {
 		if spoof != nil {
 			spoof(f, info)
@@ -44,15 +45,37 @@ func RunAnalyzerOnSrc(an *analysis.Analyzer, src string, spoofs ...func(f *ast.F
 		Analyzer:   an,
 		Fset:       fset,
 		Files:      files,
+		Pkg:        pkg,
 		TypesInfo:  info,
 		TypesSizes: types.SizesFor("gc", "amd64"),
 		Report:     func(d analysis.Diagnostic) { diags = append(diags, d) },
 		ResultOf:   map[*analysis.Analyzer]interface{}{insppass.Analyzer: inspector.New(files)},
+		// There are no dependencies to import facts from; see
+		// RunAnalyzerOnPackages for analyzers that rely on them.
+		ImportObjectFact:  func(types.Object, analysis.Fact) bool { return false },
+		ExportObjectFact:  func(types.Object, analysis.Fact) {},
+		ImportPackageFact: func(*types.Package, analysis.Fact) bool { return false },
+		ExportPackageFact: func(analysis.Fact) {},
 	}
 	_, err = an.Run(pass)
 	return diags, err
 }
 
+// SetAnalyzerFlag sets a flag of an analyzer for the duration of a test and
+// restores the previous value on cleanup.
+func SetAnalyzerFlag(tb testing.TB, an *analysis.Analyzer, name, value string) {
+	tb.Helper()
+	f := an.Flags.Lookup(name)
+	if f == nil {
+		tb.Fatalf("analyzer %s has no flag %q", an.Name, name)
+	}
+	prev := f.Value.String()
+	if err := f.Value.Set(value); err != nil {
+		tb.Fatalf("set %s.%s: %v", an.Name, name, err)
+	}
+	tb.Cleanup(func() { _ = f.Value.Set(prev) })
+}
+
 // SpoofMap maps function names to package import paths for creating fake Uses.
 type SpoofMap map[string]string
 
@@ -102,7 +125,15 @@ const (
 	PkgRestMapper        = "k8s.io/client-go/restmapper"
 	PkgControllerRuntime = "sigs.k8s.io/controller-runtime/pkg/client"
 	PkgReconcile         = "sigs.k8s.io/controller-runtime/pkg/reconcile"
+	PkgManager           = "sigs.k8s.io/controller-runtime/pkg/manager"
+	PkgBuilder           = "sigs.k8s.io/controller-runtime/pkg/builder"
+	PkgPredicate         = "sigs.k8s.io/controller-runtime/pkg/predicate"
+	PkgInformers         = "k8s.io/client-go/informers"
+	PkgCache             = "k8s.io/client-go/tools/cache"
 	PkgMetaV1            = "k8s.io/apimachinery/pkg/apis/meta/v1"
+	PkgWorkqueue         = "k8s.io/client-go/util/workqueue"
+	PkgRetry             = "k8s.io/client-go/util/retry"
+	PkgAPIErrors         = "k8s.io/apimachinery/pkg/api/errors"
 	PkgTime              = "time"
 )
 
@@ -0,0 +1,368 @@
+package analyzers
+
+import (
+	"go/constant"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+	"golang.org/x/tools/go/ssa"
+)
+
+// AnalyzerTransportReuse flags custom http.Transports given to client-go that
+// defeat its connection reuse.
+//
+// client-go caches the transport of a rest.Config without a Transport, so
+// clients built from equivalent configs share connections. Transports created
+// in the package, as http.Transport literals or clones, are followed to
+// rest.Config.Transport, to the result of a WrapTransport function, and to the
+// Transport of an http.Client passed to client-go or controller-runtime.
+// Transports reaching them are reported when they are created for each
+// client, in a loop, a wrapper or a function that may be called repeatedly,
+// disable keep-alives, carry their own TLSClientConfig, or leave
+// MaxIdleConnsPerHost at the net/http default of 2 or above MaxIdleConns.
+// Clones have the fields of the transport they are cloned from; a default
+// MaxIdleConnsPerHost is only reported for literals and clones of
+// http.DefaultTransport or of literals, whose other fields are known.
+var AnalyzerTransportReuse = &analysis.Analyzer{
+	Name:     "transportreuse",
+	Doc:      "flags custom http.Transports for Kubernetes clients created per client, disabling keep-alives, setting TLSClientConfig or misconfiguring MaxIdleConnsPerHost",
+	Run:      runTransportReuse,
+	Requires: []*analysis.Analyzer{buildssa.Analyzer},
+}
+
+// transportSink is where a transport reaches client-go.
+type transportSink struct {
+	name    string
+	wrapper bool
+}
+
+func runTransportReuse(pass *analysis.Pass) (any, error) {
+	consts := newConstTracer(pass)
+	m := newRestConfigModel(pass, consts)
+	wrappers := transportWrappers(m.funcs)
+
+	var sources []ssa.Value
+	aliases := map[ssa.Value][]ssa.Value{}
+	for _, fn := range m.funcs {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				switch instr := instr.(type) {
+				case *ssa.Alloc:
+					if isNamed(deref(instr.Type()), "net/http", "Transport") {
+						sources = append(sources, instr)
+					}
+				case *ssa.Call:
+					if isTransportClone(&instr.Call) {
+						sources = append(sources, instr)
+					}
+				}
+			}
+		}
+	}
+	for _, src := range sources {
+		aliases[src] = m.aliases(src)
+	}
+
+	for _, src := range sources {
+		sink, ok := m.transportSink(aliases[src], wrappers)
+		if !ok {
+			continue
+		}
+		fields, known := transportSourceFields(src, sources, aliases, 0)
+		instr := src.(ssa.Instruction)
+
+		if sink.wrapper || m.mayRunRepeatedly(instr, 0) {
+			pass.Reportf(src.Pos(), "http.Transport created for each client reaches %s; clients cannot share its connections, so create it once or leave Transport unset for client-go to cache",
+				sink.name)
+		}
+		if setsPointer(fields["TLSClientConfig"]) {
+			pass.Reportf(src.Pos(), "http.Transport with its own TLSClientConfig reaches %s; set TLS on rest.Config instead so client-go can cache and share the transport",
+				sink.name)
+		}
+		if isTrue(consts, fields["DisableKeepAlives"]) {
+			pass.Reportf(src.Pos(), "http.Transport reaching %s disables keep-alives, so every request opens a new TCP and TLS connection", sink.name)
+			continue
+		}
+		perHost := sameConstant(consts, fields["MaxIdleConnsPerHost"])
+		total := sameConstant(consts, fields["MaxIdleConns"])
+		switch {
+		case known && len(fields["MaxIdleConnsPerHost"]) == 0 || perHost != nil && constant.Sign(perHost) <= 0:
+			pass.Reportf(src.Pos(), "http.Transport reaching %s leaves MaxIdleConnsPerHost at the net/http default of 2, so concurrent requests to the API server keep reopening connections",
+				sink.name)
+		case perHost != nil && total != nil && constant.Sign(total) > 0 && constant.Compare(perHost, token.GTR, total):
+			pass.Reportf(src.Pos(), "http.Transport reaching %s has MaxIdleConnsPerHost (%s) above MaxIdleConns (%s), which caps it", sink.name, perHost, total)
+		}
+	}
+	return nil, nil
+}
+
+// isTransportClone reports whether common calls (*http.Transport).Clone.
+func isTransportClone(common *ssa.CallCommon) bool {
+	fn := ssaCallee(common)
+	if fn == nil || fn.Name() != "Clone" {
+		return false
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	return recv != nil && isNamed(deref(recv.Type()), "net/http", "Transport")
+}
+
+// transportSourceFields returns the values stored to each field of the
+// transport created by src, and whether they are all known: src is a
+// literal, a clone of http.DefaultTransport, or a clone of one of sources
+// whose fields are known. A clone has the fields of the transport it is
+// cloned from unless it sets them again.
+func transportSourceFields(src ssa.Value, sources []ssa.Value, aliases map[ssa.Value][]ssa.Value, depth int) (map[string][]ssa.Value, bool) {
+	fields := transportFields(aliases[src])
+	call, ok := src.(*ssa.Call)
+	if !ok {
+		return fields, true
+	}
+	base := call.Call.Args[0]
+	if isDefaultTransport(base) {
+		return fields, true
+	}
+	if depth > maxTraceDepth {
+		return fields, false
+	}
+	for _, b := range sources {
+		if b == src || !slices.Contains(aliases[b], base) {
+			continue
+		}
+		inherited, known := transportSourceFields(b, sources, aliases, depth+1)
+		for name, vals := range fields {
+			inherited[name] = vals
+		}
+		return inherited, known
+	}
+	return fields, false
+}
+
+// isDefaultTransport reports whether v is http.DefaultTransport asserted to
+// an *http.Transport.
+func isDefaultTransport(v ssa.Value) bool {
+	if ta, ok := v.(*ssa.TypeAssert); ok {
+		v = ta.X
+	}
+	load, ok := v.(*ssa.UnOp)
+	if !ok || load.Op != token.MUL {
+		return false
+	}
+	g, ok := load.X.(*ssa.Global)
+	return ok && g.Pkg != nil && g.Pkg.Pkg.Path() == "net/http" && g.Name() == "DefaultTransport"
+}
+
+// transportWrappers returns the functions assigned to rest.Config
+// WrapTransport fields or passed to rest.Config.Wrap.
+func transportWrappers(funcs []*ssa.Function) map[*ssa.Function]bool {
+	out := map[*ssa.Function]bool{}
+	add := func(v ssa.Value) {
+		switch v := v.(type) {
+		case *ssa.Function:
+			out[v] = true
+		case *ssa.MakeClosure:
+			out[v.Fn.(*ssa.Function)] = true
+		}
+	}
+	for _, fn := range funcs {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				switch instr := instr.(type) {
+				case *ssa.Store:
+					if isRestConfigField(instr.Addr, "WrapTransport") {
+						add(instr.Val)
+					}
+				case ssa.CallInstruction:
+					obj := calleeObject(instr.Common())
+					if obj != nil && obj.Pkg() != nil && obj.Pkg().Path() == PkgClientGoRest && obj.Name() == "Wrap" {
+						if args := ssaArgs(instr.Common()); len(args) == 1 {
+							add(args[0])
+						}
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+// isRestConfigField reports whether addr is the address of the named field
+// of a rest.Config.
+func isRestConfigField(addr ssa.Value, name string) bool {
+	fa, ok := addr.(*ssa.FieldAddr)
+	if !ok || !isNamed(deref(fa.X.Type()), PkgClientGoRest, "Config") {
+		return false
+	}
+	f := structField(deref(fa.X.Type()), fa.Field)
+	return f != nil && f.Name() == name
+}
+
+// transportSink returns where a transport with aliases reaches client-go.
+func (m *restConfigModel) transportSink(aliases []ssa.Value, wrappers map[*ssa.Function]bool) (transportSink, bool) {
+	for _, v := range aliases {
+		for _, ref := range *v.Referrers() {
+			switch ref := ref.(type) {
+			case *ssa.Store:
+				if ref.Val != v {
+					continue
+				}
+				if isRestConfigField(ref.Addr, "Transport") {
+					return transportSink{name: "rest.Config.Transport"}, true
+				}
+				fa, ok := ref.Addr.(*ssa.FieldAddr)
+				if !ok || !isNamed(deref(fa.X.Type()), "net/http", "Client") {
+					continue
+				}
+				if name := kubernetesHTTPClientUse(m.aliases(fa.X)); name != "" {
+					return transportSink{name: "the http.Client passed to " + name}, true
+				}
+			case *ssa.Return:
+				if wrappers[ref.Parent()] {
+					return transportSink{name: "rest.Config.WrapTransport", wrapper: true}, true
+				}
+			}
+		}
+	}
+	return transportSink{}, false
+}
+
+// kubernetesHTTPClientUse returns the client-go or controller-runtime
+// function an http.Client with aliases is passed to, or the type of the
+// options it is set in, or "".
+func kubernetesHTTPClientUse(aliases []ssa.Value) string {
+	isKubernetes := func(obj types.Object) bool {
+		return obj != nil && obj.Pkg() != nil &&
+			(strings.HasPrefix(obj.Pkg().Path(), "k8s.io/client-go/") || strings.HasPrefix(obj.Pkg().Path(), "sigs.k8s.io/controller-runtime"))
+	}
+	for _, v := range aliases {
+		for _, ref := range *v.Referrers() {
+			switch ref := ref.(type) {
+			case ssa.CallInstruction:
+				if obj := calleeObject(ref.Common()); isKubernetes(obj) {
+					return obj.Pkg().Name() + "." + obj.Name()
+				}
+			case *ssa.Store:
+				fa, ok := ref.Addr.(*ssa.FieldAddr)
+				if !ok || ref.Val != v {
+					continue
+				}
+				if n, ok := deref(fa.X.Type()).(*types.Named); ok && isKubernetes(n.Obj()) {
+					return n.Obj().Pkg().Name() + "." + n.Obj().Name()
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// transportFields returns the values stored to each field of a transport
+// with aliases.
+func transportFields(aliases []ssa.Value) map[string][]ssa.Value {
+	out := map[string][]ssa.Value{}
+	for _, v := range aliases {
+		for _, ref := range *v.Referrers() {
+			fa, ok := ref.(*ssa.FieldAddr)
+			if !ok {
+				continue
+			}
+			f := structField(deref(fa.X.Type()), fa.Field)
+			if f == nil {
+				continue
+			}
+			for _, st := range *fa.Referrers() {
+				if st, ok := st.(*ssa.Store); ok && st.Addr == fa {
+					out[f.Name()] = append(out[f.Name()], st.Val)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// setsPointer reports whether one of vals is not a nil constant.
+func setsPointer(vals []ssa.Value) bool {
+	for _, v := range vals {
+		if c, ok := v.(*ssa.Const); !ok || !c.IsNil() {
+			return true
+		}
+	}
+	return false
+}
+
+// isTrue reports whether one of vals resolves to true.
+func isTrue(consts *constTracer, vals []ssa.Value) bool {
+	for _, v := range vals {
+		if c := consts.value(v, 0); c != nil && c.Kind() == constant.Bool && constant.BoolVal(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameConstant returns the integer constant all of vals resolve to, or nil.
+func sameConstant(consts *constTracer, vals []ssa.Value) constant.Value {
+	if c := consts.same(vals, 0); c != nil && c.Kind() == constant.Int {
+		return c
+	}
+	return nil
+}
+
+// mayRunRepeatedly reports whether instr may execute more than once: in a
+// loop, a closure other than one called in place or by sync.Once, or a
+// function with several or repeated call sites in the package. Exported
+// functions of library packages are assumed to be called once per client.
+func (m *restConfigModel) mayRunRepeatedly(instr ssa.Instruction, depth int) bool {
+	if inLoop(instr.Block()) {
+		return true
+	}
+	fn := instr.Parent()
+	switch {
+	case depth > maxTraceDepth:
+		return false
+	case fn.Parent() != nil:
+		var closures []*ssa.MakeClosure
+		for _, site := range m.sites[fn] {
+			if mc, ok := site.(*ssa.MakeClosure); ok {
+				closures = append(closures, mc)
+			}
+		}
+		if len(closures) == 1 && calledOnce(closures[0]) {
+			return m.mayRunRepeatedly(closures[0], depth+1)
+		}
+		if len(closures) > 0 {
+			return true
+		}
+	case fn.Signature.Recv() == nil && (strings.HasPrefix(fn.Name(), "init") || fn.Name() == "main" && fn.Pkg.Pkg.Name() == "main"):
+		return false
+	}
+	sites := m.sites[fn]
+	switch len(sites) {
+	case 0:
+		return fn.Object() != nil && fn.Object().Exported() && fn.Pkg.Pkg.Name() != "main"
+	case 1:
+		return m.mayRunRepeatedly(sites[0], depth+1)
+	}
+	return true
+}
+
+// calledOnce reports whether the closure mc is only called in place or
+// passed to sync.Once.Do, so it runs at most once each time it is created.
+func calledOnce(mc *ssa.MakeClosure) bool {
+	for _, ref := range *mc.Referrers() {
+		call, ok := ref.(*ssa.Call)
+		if !ok {
+			return false
+		}
+		if call.Call.Value == mc {
+			continue
+		}
+		fn := ssaCallee(&call.Call)
+		if fn == nil || fn.Pkg() == nil || fn.Pkg().Path() != "sync" || fn.Name() != "Do" {
+			return false
+		}
+	}
+	return true
+}
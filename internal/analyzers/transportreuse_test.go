@@ -0,0 +1,175 @@
+package analyzers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/amisstea/k8s-client-audit/internal/analyzers/testutil"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+const transportReuseImports = `package p
+
+import (
+	"crypto/tls"
+	"net/http"
+	"sync"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+var (
+	_ tls.Config
+	_ sync.Once
+	_ = kubernetes.NewForConfig
+	_ rest.Config
+	_ http.RoundTripper
+)
+`
+
+func runTransportReuseOnSrc(t *testing.T, src string) []analysis.Diagnostic {
+	t.Helper()
+	res, err := testutil.RunAnalyzerOnStubbedSrc(AnalyzerTransportReuse, transportReuseImports+src)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	return res.Diagnostics
+}
+
+func TestTransportReuse_PerClientTransports_Flagged(t *testing.T) {
+	diags := runTransportReuseOnSrc(t, `
+func wrapped(cfg *rest.Config) {
+	cfg.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
+		return &http.Transport{MaxIdleConnsPerHost: 50}
+	}
+}
+
+func perTenant(cfg *rest.Config, tenants []string) {
+	for range tenants {
+		c := rest.CopyConfig(cfg)
+		c.Transport = &http.Transport{MaxIdleConnsPerHost: 50}
+		_, _ = kubernetes.NewForConfig(c)
+	}
+}
+
+func withClient(cfg *rest.Config) {
+	hc := &http.Client{Transport: &http.Transport{MaxIdleConnsPerHost: 50}}
+	_, _ = kubernetes.NewForConfigAndClient(cfg, hc)
+}
+
+func Build(cfg *rest.Config) { withClient(cfg) }
+`)
+	want := []string{
+		"reaches rest.Config.WrapTransport",
+		"reaches rest.Config.Transport",
+		"reaches the http.Client passed to kubernetes.NewForConfigAndClient",
+	}
+	if len(diags) != len(want) {
+		t.Fatalf("expected %d diagnostics, got %d: %v", len(want), len(diags), diags)
+	}
+	for i, w := range want {
+		if !strings.Contains(diags[i].Message, "created for each client") || !strings.Contains(diags[i].Message, w) {
+			t.Errorf("diagnostic %d: expected %q, got %q", i, w, diags[i].Message)
+		}
+	}
+}
+
+func TestTransportReuse_Misconfiguration_Flagged(t *testing.T) {
+	diags := runTransportReuseOnSrc(t, `
+const perHost = 0
+
+func main() {
+	cfg, _ := rest.InClusterConfig()
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.DisableKeepAlives = true
+	cfg.Transport = tr
+
+	other := rest.CopyConfig(cfg)
+	other.Transport = &http.Transport{TLSClientConfig: &tls.Config{}, MaxIdleConnsPerHost: perHost}
+
+	third := rest.CopyConfig(cfg)
+	third.Transport = &http.Transport{MaxIdleConns: 10, MaxIdleConnsPerHost: 20}
+}
+`)
+	want := []string{
+		"disables keep-alives",
+		"with its own TLSClientConfig",
+		"leaves MaxIdleConnsPerHost at the net/http default of 2",
+		"MaxIdleConnsPerHost (20) above MaxIdleConns (10)",
+	}
+	if len(diags) != len(want) {
+		t.Fatalf("expected %d diagnostics, got %d: %v", len(want), len(diags), diags)
+	}
+	for i, w := range want {
+		if !strings.Contains(diags[i].Message, w) {
+			t.Errorf("diagnostic %d: expected %q, got %q", i, w, diags[i].Message)
+		}
+	}
+}
+
+func TestTransportReuse_SharedTransport_NotFlagged(t *testing.T) {
+	diags := runTransportReuseOnSrc(t, `
+var (
+	once   sync.Once
+	shared *http.Transport
+)
+
+func transport() *http.Transport {
+	once.Do(func() {
+		shared = &http.Transport{MaxIdleConns: 100, MaxIdleConnsPerHost: 50}
+	})
+	return shared
+}
+
+func main() {
+	cfg, _ := rest.InClusterConfig()
+	cfg.Transport = transport()
+	_, _ = kubernetes.NewForConfig(cfg)
+
+	// Transports that never reach client-go are not considered.
+	_ = &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+}
+`)
+	if len(diags) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", diags)
+	}
+}
+
+func TestTransportReuse_Clones_InheritFields(t *testing.T) {
+	diags := runTransportReuseOnSrc(t, `
+func main() {
+	cfg, _ := rest.InClusterConfig()
+
+	base := &http.Transport{MaxIdleConns: 100, MaxIdleConnsPerHost: 50}
+	tuned := base.Clone()
+	tuned.IdleConnTimeout = 0
+	cfg.Transport = tuned
+
+	other := rest.CopyConfig(cfg)
+	other.Transport = http.DefaultTransport.(*http.Transport).Clone()
+
+	secure := &http.Transport{TLSClientConfig: &tls.Config{}, MaxIdleConnsPerHost: 50}
+	third := rest.CopyConfig(cfg)
+	third.Transport = secure.Clone()
+}
+
+// The fields of a transport passed in are unknown.
+func configure(cfg *rest.Config, from *http.Transport) {
+	cfg.Transport = from.Clone()
+}
+`)
+	want := []string{
+		"leaves MaxIdleConnsPerHost at the net/http default of 2",
+		"with its own TLSClientConfig",
+	}
+	if len(diags) != len(want) {
+		t.Fatalf("expected %d diagnostics, got %d: %v", len(want), len(diags), diags)
+	}
+	for i, w := range want {
+		if !strings.Contains(diags[i].Message, w) {
+			t.Errorf("diagnostic %d: expected %q, got %q", i, w, diags[i].Message)
+		}
+	}
+}
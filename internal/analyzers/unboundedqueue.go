@@ -2,6 +2,7 @@ package analyzers
 
 import (
 	"go/ast"
+	"go/token"
 	"go/types"
 
 	"golang.org/x/tools/go/analysis"
@@ -35,15 +36,148 @@ func runUnboundedQueue(pass *analysis.Pass) (any, error) {
 		return false
 	}
 	insp := pass.ResultOf[insppass.Analyzer].(*inspector.Inspector)
-	insp.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+	insp.WithStack([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
 		ce := n.(*ast.CallExpr)
 		if obj := pass.TypesInfo.Uses[calleeIdent(ce.Fun)]; obj != nil {
 			if isFromWorkqueue(obj, "New", "NewNamed") {
-				pass.Reportf(ce.Lparen, "Workqueue constructed without a rate limiter; use NewRateLimitingQueue or a RateLimitingInterface")
+				d := analysis.Diagnostic{
+					Pos:     ce.Lparen,
+					Message: "Workqueue constructed without a rate limiter; use NewRateLimitingQueue or a RateLimitingInterface",
+				}
+				if usedAsInterface(pass.TypesInfo, ce, stack) {
+					d.SuggestedFixes = rateLimitedQueueFix(ce, obj.Name())
+				}
+				pass.Report(d)
 			}
 		}
+		return true
 	})
 	return nil, nil
 }
 
-// calleeIdent now provided in helpers.go
+// usedAsInterface reports whether the value of the call ce, the innermost
+// node of stack, is used as an interface: returned as one by the call, or
+// directly assigned, passed or returned where one is expected. Only then can
+// the RateLimitingInterface of the rate-limited constructors replace it; a
+// *workqueue.Type variable or field would no longer compile.
+func usedAsInterface(info *types.Info, ce *ast.CallExpr, stack []ast.Node) bool {
+	t := info.TypeOf(ce)
+	if t == nil {
+		return false
+	}
+	if types.IsInterface(t) {
+		return true
+	}
+	var target types.Type
+	for i := len(stack) - 2; i >= 0 && target == nil; i-- {
+		switch parent := stack[i].(type) {
+		case *ast.ParenExpr:
+			continue
+		case *ast.AssignStmt:
+			if parent.Tok == token.ASSIGN && len(parent.Lhs) == len(parent.Rhs) {
+				for j, rhs := range parent.Rhs {
+					if ast.Unparen(rhs) == ce {
+						target = info.TypeOf(parent.Lhs[j])
+					}
+				}
+			}
+		case *ast.ValueSpec:
+			if parent.Type != nil {
+				target = info.TypeOf(parent.Type)
+			}
+		case *ast.KeyValueExpr:
+			if key, ok := parent.Key.(*ast.Ident); ok && ast.Unparen(parent.Value) == ce {
+				if field, ok := info.Uses[key].(*types.Var); ok && field.IsField() {
+					target = field.Type()
+				}
+			}
+		case *ast.CallExpr:
+			sig, ok := info.TypeOf(parent.Fun).(*types.Signature)
+			if !ok {
+				return false
+			}
+			for j, arg := range parent.Args {
+				if ast.Unparen(arg) != ce {
+					continue
+				}
+				switch {
+				case j < sig.Params().Len()-1 || j < sig.Params().Len() && !sig.Variadic():
+					target = sig.Params().At(j).Type()
+				case sig.Variadic() && !parent.Ellipsis.IsValid():
+					target = sig.Params().At(sig.Params().Len() - 1).Type().(*types.Slice).Elem()
+				}
+			}
+		case *ast.ReturnStmt:
+			target = returnTarget(info, parent, ce, stack[:i])
+		}
+		if target == nil {
+			return false
+		}
+	}
+	return target != nil && types.IsInterface(target)
+}
+
+// returnTarget returns the result type of the function in stack that ret
+// returns ce as, or nil.
+func returnTarget(info *types.Info, ret *ast.ReturnStmt, ce *ast.CallExpr, stack []ast.Node) types.Type {
+	var results *types.Tuple
+	for i := len(stack) - 1; i >= 0 && results == nil; i-- {
+		switch fn := stack[i].(type) {
+		case *ast.FuncLit:
+			if sig, ok := info.TypeOf(fn).(*types.Signature); ok {
+				results = sig.Results()
+			}
+		case *ast.FuncDecl:
+			if obj, ok := info.Defs[fn.Name].(*types.Func); ok {
+				results = obj.Type().(*types.Signature).Results()
+			}
+		}
+	}
+	if results == nil || results.Len() != len(ret.Results) {
+		return nil
+	}
+	for i, r := range ret.Results {
+		if ast.Unparen(r) == ce {
+			return results.At(i).Type()
+		}
+	}
+	return nil
+}
+
+// rateLimitedQueueFix rewrites workqueue.New() and workqueue.NewNamed(name)
+// to the rate-limited constructors with the default controller rate limiter.
+// The RateLimitingInterface they return implements workqueue.Interface, so
+// callers using the queue through that interface keep compiling.
+func rateLimitedQueueFix(ce *ast.CallExpr, name string) []analysis.SuggestedFix {
+	qualifier := ""
+	if sel, ok := ce.Fun.(*ast.SelectorExpr); ok {
+		pkg, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return nil
+		}
+		qualifier = pkg.Name
+	}
+	limiter := qualify(qualifier, "DefaultControllerRateLimiter") + "()"
+	var edit analysis.TextEdit
+	switch {
+	case name == "New" && len(ce.Args) == 0:
+		edit = analysis.TextEdit{
+			Pos:     ce.Pos(),
+			End:     ce.End(),
+			NewText: []byte(qualify(qualifier, "NewRateLimitingQueue") + "(" + limiter + ")"),
+		}
+	case name == "NewNamed" && len(ce.Args) == 1:
+		// Keep the name argument as written.
+		edit = analysis.TextEdit{
+			Pos:     ce.Pos(),
+			End:     ce.Args[0].Pos(),
+			NewText: []byte(qualify(qualifier, "NewNamedRateLimitingQueue") + "(" + limiter + ", "),
+		}
+	default:
+		return nil
+	}
+	return []analysis.SuggestedFix{{Message: "Use a rate-limited workqueue", TextEdits: []analysis.TextEdit{edit}}}
+}
@@ -1,6 +1,7 @@
 package analyzers
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/amisstea/k8s-client-audit/internal/analyzers/testutil"
@@ -47,3 +48,63 @@ func f(){ _ = New() }`
 		t.Fatalf("did not expect diagnostic for non-workqueue New")
 	}
 }
+
+func TestUnboundedQueue_SuggestedFix_RateLimited(t *testing.T) {
+	src := `package p
+
+import wq "k8s.io/client-go/util/workqueue"
+
+type controller struct{ queue wq.Interface }
+
+func newController() *controller {
+	c := &controller{queue: wq.New()}
+	var named wq.Interface = wq.NewNamed("pods")
+	named.ShutDown()
+	return c
+}
+`
+	res, err := testutil.RunAnalyzerOnStubbedSrc(AnalyzerUnboundedQueue, src)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(res.Diagnostics) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %d", len(res.Diagnostics))
+	}
+	fixed := testutil.MustApplyFixes(t, res)["p"]
+	for _, want := range []string{
+		"wq.NewRateLimitingQueue(wq.DefaultControllerRateLimiter())",
+		`wq.NewNamedRateLimitingQueue(wq.DefaultControllerRateLimiter(), "pods")`,
+	} {
+		if !strings.Contains(fixed, want) {
+			t.Fatalf("expected fixed source to contain %q:\n%s", want, fixed)
+		}
+	}
+}
+
+func TestUnboundedQueue_ConcreteQueue_NoFix(t *testing.T) {
+	src := `package p
+
+import wq "k8s.io/client-go/util/workqueue"
+
+type controller struct{ queue *wq.Type }
+
+func newController() *controller {
+	c := &controller{queue: wq.New()}
+	q := wq.NewNamed("pods")
+	c.queue = q
+	return c
+}
+`
+	res, err := testutil.RunAnalyzerOnStubbedSrc(AnalyzerUnboundedQueue, src)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(res.Diagnostics) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %d", len(res.Diagnostics))
+	}
+	for _, d := range res.Diagnostics {
+		if len(d.SuggestedFixes) != 0 {
+			t.Fatalf("expected no fix for a *workqueue.Type, got %v", d.SuggestedFixes)
+		}
+	}
+}
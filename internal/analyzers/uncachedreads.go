@@ -0,0 +1,300 @@
+package analyzers
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+	"golang.org/x/tools/go/ssa"
+)
+
+// AnalyzerUncachedReads flags Get/List calls in Reconcile and other hot paths
+// that go through a controller-runtime client bypassing the manager's cache.
+//
+// Uncached clients are those obtained from the manager's GetAPIReader, from
+// client.New, or from client.WithFieldOwner wrapping either. They are
+// followed through local and package-level variables, struct fields assigned
+// anywhere in the package, closures and functions returning them, including
+// functions of other packages via facts.
+var AnalyzerUncachedReads = &analysis.Analyzer{
+	Name:      "uncachedreads",
+	Doc:       "flags Get/List through uncached controller-runtime clients (GetAPIReader, client.New) in Reconcile and other hot paths",
+	Run:       runUncachedReads,
+	Requires:  []*analysis.Analyzer{buildssa.Analyzer},
+	FactTypes: []analysis.Fact{(*returnsUncachedClientFact)(nil)},
+}
+
+// returnsUncachedClientFact marks a function that returns a client bypassing
+// the cache. Origin names where the client comes from.
+type returnsUncachedClientFact struct{ Origin string }
+
+func (*returnsUncachedClientFact) AFact() {}
+
+func (f *returnsUncachedClientFact) String() string {
+	return "returns an uncached client from " + f.Origin
+}
+
+// uncachedReadAllow lists functions allowed to read without the cache, such
+// as deliberate consistency reads. Entries are written as for hot-paths.
+var uncachedReadAllow stringListFlag
+
+func init() {
+	AnalyzerUncachedReads.Flags.Var(&uncachedReadAllow, "allow", "comma-separated functions allowed to read through uncached clients, e.g. for deliberate consistency reads")
+}
+
+func runUncachedReads(pass *analysis.Pass) (any, error) {
+	ssaInfo := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+
+	funcs := ssaInfo.SrcFuncs
+	if init := ssaInfo.Pkg.Func("init"); init != nil {
+		funcs = append(funcs[:len(funcs):len(funcs)], init)
+	}
+	tracer := &uncachedTracer{
+		pass:    pass,
+		fields:  map[*types.Var]string{},
+		globals: map[*ssa.Global]string{},
+		funcs:   map[*ssa.Function]string{},
+	}
+	tracer.solve(funcs)
+
+	for fn, origin := range tracer.funcs {
+		if obj, ok := fn.Object().(*types.Func); ok && obj.Pkg() == pass.Pkg {
+			pass.ExportObjectFact(obj, &returnsUncachedClientFact{Origin: origin})
+		}
+	}
+
+	for _, fn := range ssaInfo.SrcFuncs {
+		hot := fn
+		for hot.Parent() != nil {
+			hot = hot.Parent()
+		}
+		fd, ok := hot.Syntax().(*ast.FuncDecl)
+		if !ok || !isHotPath(pass, fd) || isAllowedUncachedRead(pass, fd) {
+			continue
+		}
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				ci, ok := instr.(ssa.CallInstruction)
+				if !ok {
+					continue
+				}
+				method, recv := clientReadCall(ci.Common())
+				if recv == nil {
+					continue
+				}
+				if origin := tracer.origin(recv, 0); origin != "" {
+					pass.Reportf(ci.Pos(), "%s through uncached client from %s in %s bypasses the informer cache; use the manager's client or allow deliberate consistency reads with -allow",
+						method, origin, fd.Name.Name)
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// isAllowedUncachedRead reports whether fd is listed in uncachedReadAllow.
+func isAllowedUncachedRead(pass *analysis.Pass, fd *ast.FuncDecl) bool {
+	obj := pass.TypesInfo.Defs[fd.Name]
+	if obj == nil {
+		return false
+	}
+	sig, ok := obj.Type().(*types.Signature)
+	return ok && matchesFuncList(uncachedReadAllow, obj, sig)
+}
+
+// clientReadCall returns the method name and receiver of a controller-runtime
+// Get or List call, or a nil receiver.
+func clientReadCall(common *ssa.CallCommon) (string, ssa.Value) {
+	callee := ssaCallee(common)
+	if callee == nil || !isKubernetesMethodCall(callee, "Get", "List") || callee.Pkg().Path() != PkgControllerRuntimeClient {
+		return "", nil
+	}
+	return callee.Name(), ssaReceiver(common)
+}
+
+// uncachedTracer decides whether a value is a client bypassing the cache and
+// names its origin.
+type uncachedTracer struct {
+	pass    *analysis.Pass
+	fields  map[*types.Var]string
+	globals map[*ssa.Global]string
+	funcs   map[*ssa.Function]string // functions returning an uncached client
+}
+
+// solve records the struct fields, package-level variables and functions of
+// funcs that hold or return an uncached client, until nothing changes.
+func (t *uncachedTracer) solve(funcs []*ssa.Function) {
+	for changed := true; changed; {
+		changed = false
+		for _, fn := range funcs {
+			for _, b := range fn.Blocks {
+				for _, instr := range b.Instrs {
+					switch instr := instr.(type) {
+					case *ssa.Store:
+						var key *types.Var
+						switch addr := instr.Addr.(type) {
+						case *ssa.FieldAddr:
+							key = structField(deref(addr.X.Type()), addr.Field)
+						case *ssa.Global:
+							if t.globals[addr] == "" {
+								if origin := t.origin(instr.Val, 0); origin != "" {
+									t.globals[addr] = origin
+									changed = true
+								}
+							}
+						}
+						if key != nil && t.fields[key] == "" {
+							if origin := t.origin(instr.Val, 0); origin != "" {
+								t.fields[key] = origin
+								changed = true
+							}
+						}
+					case *ssa.Return:
+						if t.funcs[fn] != "" {
+							continue
+						}
+						for _, r := range instr.Results {
+							if origin := t.origin(r, 0); origin != "" {
+								t.funcs[fn] = origin
+								changed = true
+								break
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func (t *uncachedTracer) origin(v ssa.Value, depth int) string {
+	if depth > maxTraceDepth {
+		return ""
+	}
+	depth++
+	switch v := v.(type) {
+	case *ssa.Call:
+		return t.callOrigin(&v.Call, depth)
+	case *ssa.Extract:
+		// c, err := client.New(cfg, opts)
+		if call, ok := v.Tuple.(*ssa.Call); ok && v.Index == 0 {
+			return t.callOrigin(&call.Call, depth)
+		}
+	case *ssa.Phi:
+		for _, e := range v.Edges {
+			if origin := t.origin(e, depth); origin != "" {
+				return origin
+			}
+		}
+	case *ssa.ChangeInterface:
+		return t.origin(v.X, depth)
+	case *ssa.MakeInterface:
+		return t.origin(v.X, depth)
+	case *ssa.ChangeType:
+		return t.origin(v.X, depth)
+	case *ssa.TypeAssert:
+		return t.origin(v.X, depth)
+	case *ssa.Field:
+		if f := structField(v.X.Type(), v.Field); f != nil {
+			return t.fields[f]
+		}
+	case *ssa.UnOp:
+		if v.Op == token.MUL {
+			return t.addrOrigin(v.X, depth)
+		}
+	case *ssa.FreeVar:
+		if bound := boundValue(v); bound != nil {
+			return t.origin(bound, depth)
+		}
+	}
+	return ""
+}
+
+// addrOrigin returns the origin of an uncached client stored at addr.
+func (t *uncachedTracer) addrOrigin(addr ssa.Value, depth int) string {
+	switch addr := addr.(type) {
+	case *ssa.Global:
+		return t.globals[addr]
+	case *ssa.FieldAddr:
+		if f := structField(deref(addr.X.Type()), addr.Field); f != nil {
+			return t.fields[f]
+		}
+	case *ssa.Alloc:
+		for _, ref := range *addr.Referrers() {
+			if st, ok := ref.(*ssa.Store); ok && st.Addr == addr {
+				if origin := t.origin(st.Val, depth); origin != "" {
+					return origin
+				}
+			}
+		}
+	case *ssa.FreeVar:
+		if bound := boundValue(addr); bound != nil {
+			return t.addrOrigin(bound, depth)
+		}
+	}
+	return ""
+}
+
+func (t *uncachedTracer) callOrigin(common *ssa.CallCommon, depth int) string {
+	if common.IsInvoke() {
+		if isAPIReaderGetter(common.Method) {
+			return "GetAPIReader"
+		}
+		return ""
+	}
+	callee := common.StaticCallee()
+	if callee == nil {
+		return ""
+	}
+	if origin := t.funcs[callee]; origin != "" {
+		return origin
+	}
+	obj, ok := callee.Object().(*types.Func)
+	if !ok || obj.Pkg() == nil {
+		return ""
+	}
+	if isAPIReaderGetter(obj) {
+		return "GetAPIReader"
+	}
+	if obj.Pkg().Path() == PkgControllerRuntimeClient {
+		switch obj.Name() {
+		case "New":
+			return "client.New"
+		case "WithFieldOwner":
+			if len(common.Args) > 0 {
+				return t.origin(common.Args[0], depth)
+			}
+		}
+		return ""
+	}
+	if obj.Pkg() != t.pass.Pkg {
+		var fact returnsUncachedClientFact
+		if t.pass.ImportObjectFact(obj, &fact) {
+			return fact.Origin
+		}
+	}
+	return ""
+}
+
+// isAPIReaderGetter reports whether fn is the GetAPIReader method of a
+// controller-runtime manager or cluster.
+func isAPIReaderGetter(fn *types.Func) bool {
+	if fn == nil || fn.Pkg() == nil || fn.Name() != "GetAPIReader" {
+		return false
+	}
+	switch fn.Pkg().Path() {
+	case PkgControllerRuntimeManager, PkgControllerRuntimeCluster:
+		return true
+	}
+	return false
+}
+
+// structField returns the i-th field of the struct type t, or nil.
+func structField(t types.Type, i int) *types.Var {
+	if s, ok := t.Underlying().(*types.Struct); ok && i < s.NumFields() {
+		return s.Field(i)
+	}
+	return nil
+}
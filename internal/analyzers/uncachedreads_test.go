@@ -0,0 +1,157 @@
+package analyzers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/amisstea/k8s-client-audit/internal/analyzers/testutil"
+)
+
+const uncachedReconcilerSrc = `package p
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type Reconciler struct {
+	Client    client.Client
+	APIReader client.Reader
+	direct    client.Client
+}
+
+func Setup(mgr manager.Manager) (*Reconciler, error) {
+	direct, err := client.New(nil, client.Options{})
+	if err != nil {
+		return nil, err
+	}
+	return &Reconciler{
+		Client:    mgr.GetClient(),
+		APIReader: mgr.GetAPIReader(),
+		direct:    client.WithFieldOwner(direct, "me"),
+	}, nil
+}
+
+func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	pod := &corev1.Pod{}
+	if err := r.Client.Get(ctx, req.NamespacedName, pod); err != nil {
+		return reconcile.Result{}, err
+	}
+	if err := r.APIReader.Get(ctx, req.NamespacedName, pod); err != nil {
+		return reconcile.Result{}, err
+	}
+	pods := &corev1.PodList{}
+	if err := r.direct.List(ctx, pods); err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, nil
+}
+
+func (r *Reconciler) setup(ctx context.Context, req reconcile.Request) error {
+	return r.APIReader.Get(ctx, req.NamespacedName, &corev1.Pod{})
+}
+`
+
+func TestUncachedReads_ReconcileFlagged(t *testing.T) {
+	res, err := testutil.RunAnalyzerOnStubbedSrc(AnalyzerUncachedReads, uncachedReconcilerSrc)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(res.Diagnostics) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %d: %v", len(res.Diagnostics), res.Diagnostics)
+	}
+	if msg := res.Diagnostics[0].Message; !strings.Contains(msg, "Get through uncached client from GetAPIReader") {
+		t.Fatalf("unexpected message %q", msg)
+	}
+	if msg := res.Diagnostics[1].Message; !strings.Contains(msg, "List through uncached client from client.New") {
+		t.Fatalf("unexpected message %q", msg)
+	}
+}
+
+func TestUncachedReads_AllowedFunction_NoDiag(t *testing.T) {
+	testutil.SetAnalyzerFlag(t, AnalyzerUncachedReads, "allow", "Reconciler.Reconcile")
+	res, err := testutil.RunAnalyzerOnStubbedSrc(AnalyzerUncachedReads, uncachedReconcilerSrc)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(res.Diagnostics) != 0 {
+		t.Fatalf("expected 0 diagnostics, got %v", res.Diagnostics)
+	}
+}
+
+func TestUncachedReads_ConstructorInOtherPackage_Flagged(t *testing.T) {
+	helpers := `package clients
+
+import "sigs.k8s.io/controller-runtime/pkg/client"
+
+func Direct() (client.Client, error) { return client.New(nil, client.Options{}) }
+`
+	src := `package p
+
+import (
+	"context"
+
+	"example.com/clients"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type Reconciler struct{}
+
+func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	c, err := clients.Direct()
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, c.Get(ctx, req.NamespacedName, &corev1.Pod{})
+}
+`
+	res, err := testutil.RunAnalyzerOnPackages(AnalyzerUncachedReads, map[string]string{
+		"example.com/clients": helpers,
+		"p":                   src,
+	})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(res.Diagnostics) != 1 || !strings.Contains(res.Diagnostics[0].Message, "client.New") {
+		t.Fatalf("expected one diagnostic naming client.New, got %v", res.Diagnostics)
+	}
+}
+
+func TestUncachedReads_BuiltinAndFuncValueCalls_NoCrash(t *testing.T) {
+	res, err := testutil.RunAnalyzerOnStubbedSrc(AnalyzerUncachedReads, `package p
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type Reconciler struct {
+	APIReader client.Reader
+	names     []string
+	check     func(*corev1.Pod) error
+}
+
+func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	r.names = append(r.names, req.Name)
+	pod := &corev1.Pod{}
+	if err := r.check(pod); err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, nil
+}
+`)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(res.Diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", res.Diagnostics)
+	}
+}
@@ -0,0 +1,207 @@
+// Package baseline records accepted diagnostics so that only new findings
+// are reported, allowing existing debt to be ratcheted down incrementally.
+//
+// Diagnostics are keyed by analyzer, package, enclosing function and a
+// fingerprint of the normalized source line and message, rather than by
+// file position, so a baseline survives unrelated edits that shift lines.
+package baseline
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Version is the current baseline file format version.
+const Version = 1
+
+// Key identifies a diagnostic independently of its line number.
+type Key struct {
+	Analyzer    string `json:"analyzer"`
+	Package     string `json:"package"`
+	Function    string `json:"function,omitempty"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// Entry is a baselined diagnostic and the number of times it occurs.
+type Entry struct {
+	Key
+	Count int `json:"count"`
+	// Message is informational and not part of the key.
+	Message string `json:"message"`
+}
+
+// Baseline is the content of a baseline file.
+type Baseline struct {
+	Version int     `json:"version"`
+	Entries []Entry `json:"entries"`
+}
+
+// Load reads a baseline file.
+func Load(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if b.Version != Version {
+		return nil, fmt.Errorf("%s: unsupported baseline version %d", path, b.Version)
+	}
+	return &b, nil
+}
+
+// Write writes b to path with entries in a stable order.
+func (b *Baseline) Write(path string) error {
+	sort.Slice(b.Entries, func(i, j int) bool {
+		x, y := b.Entries[i].Key, b.Entries[j].Key
+		if x.Package != y.Package {
+			return x.Package < y.Package
+		}
+		if x.Function != y.Function {
+			return x.Function < y.Function
+		}
+		if x.Analyzer != y.Analyzer {
+			return x.Analyzer < y.Analyzer
+		}
+		return x.Fingerprint < y.Fingerprint
+	})
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(b); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// Add records one occurrence of the diagnostic identified by k.
+func (b *Baseline) Add(k Key, message string) {
+	if b.Version == 0 {
+		b.Version = Version
+	}
+	for i := range b.Entries {
+		if b.Entries[i].Key == k {
+			b.Entries[i].Count++
+			return
+		}
+	}
+	b.Entries = append(b.Entries, Entry{Key: k, Count: 1, Message: message})
+}
+
+// Matcher consumes baseline entries as matching diagnostics are found.
+type Matcher struct {
+	remaining map[Key]int
+}
+
+// Matcher returns a matcher over the entries of b.
+func (b *Baseline) Matcher() *Matcher {
+	m := &Matcher{remaining: map[Key]int{}}
+	for _, e := range b.Entries {
+		m.remaining[e.Key] += e.Count
+	}
+	return m
+}
+
+// Match reports whether a diagnostic identified by k is in the baseline.
+// Each baseline entry matches at most Count diagnostics; any more are new.
+func (m *Matcher) Match(k Key) bool {
+	if m.remaining[k] == 0 {
+		return false
+	}
+	m.remaining[k]--
+	return true
+}
+
+// KeyFor computes the key of a diagnostic at pos with the given message.
+// files is the syntax of the package and src the content of the file
+// containing pos.
+func KeyFor(analyzer, pkgPath string, fset *token.FileSet, files []*ast.File, pos token.Pos, message string, src []byte) Key {
+	return Key{
+		Analyzer:    analyzer,
+		Package:     pkgPath,
+		Function:    enclosingFunc(files, pos),
+		Fingerprint: Fingerprint(sourceLine(src, fset.Position(pos).Line), message),
+	}
+}
+
+// Fingerprint hashes a source line, with whitespace normalized, and a
+// diagnostic message.
+func Fingerprint(code, message string) string {
+	h := sha256.New()
+	h.Write([]byte(strings.Join(strings.Fields(code), " ")))
+	h.Write([]byte{0})
+	h.Write([]byte(message))
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// enclosingFunc names the top-level function declaration containing pos as
+// "Func" or "Recv.Method", or returns "" at package level.
+func enclosingFunc(files []*ast.File, pos token.Pos) string {
+	for _, f := range files {
+		if pos < f.Pos() || pos > f.End() {
+			continue
+		}
+		for _, d := range f.Decls {
+			fd, ok := d.(*ast.FuncDecl)
+			if !ok || pos < fd.Pos() || pos > fd.End() {
+				continue
+			}
+			if fd.Recv != nil && len(fd.Recv.List) > 0 {
+				return recvName(fd.Recv.List[0].Type) + "." + fd.Name.Name
+			}
+			return fd.Name.Name
+		}
+	}
+	return ""
+}
+
+func recvName(expr ast.Expr) string {
+	switch x := expr.(type) {
+	case *ast.StarExpr:
+		return recvName(x.X)
+	case *ast.IndexExpr:
+		return recvName(x.X)
+	case *ast.IndexListExpr:
+		return recvName(x.X)
+	case *ast.ParenExpr:
+		return recvName(x.X)
+	case *ast.Ident:
+		return x.Name
+	}
+	return types.ExprString(expr)
+}
+
+func sourceLine(src []byte, line int) string {
+	for i := 1; i < line; i++ {
+		nl := bytes.IndexByte(src, '\n')
+		if nl < 0 {
+			return ""
+		}
+		src = src[nl+1:]
+	}
+	if nl := bytes.IndexByte(src, '\n'); nl >= 0 {
+		src = src[:nl]
+	}
+	return string(src)
+}
+
+// ID returns a short stable hash of k, for use as an external fingerprint.
+func (k Key) ID() string {
+	h := sha256.New()
+	for _, s := range []string{k.Analyzer, k.Package, k.Function, k.Fingerprint} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
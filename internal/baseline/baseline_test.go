@@ -0,0 +1,86 @@
+package baseline
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const before = `package a
+
+func (r *Reconciler) Reconcile() {
+	_ = c.List(ctx, &pods)
+}
+`
+
+// after shifts the flagged line down and changes its indentation.
+const after = `package a
+
+import "fmt"
+
+// Reconcile reconciles.
+func (r *Reconciler) Reconcile() {
+	fmt.Println("start")
+		_ = c.List(ctx,   &pods)
+}
+`
+
+func keyAt(t *testing.T, src, needle string) Key {
+	t.Helper()
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "a.go", src, 0)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	pos := fset.File(f.Pos()).Pos(strings.Index(src, needle))
+	return KeyFor("noselectors", "example.com/a", fset, []*ast.File{f}, pos, "List without options", []byte(src))
+}
+
+func TestKeyFor_StableAcrossLineShifts(t *testing.T) {
+	k1 := keyAt(t, before, "List")
+	k2 := keyAt(t, after, "List")
+	if k1 != k2 {
+		t.Fatalf("expected equal keys, got %+v and %+v", k1, k2)
+	}
+	if k1.Function != "Reconciler.Reconcile" {
+		t.Fatalf("unexpected enclosing function %q", k1.Function)
+	}
+}
+
+func TestMatcher_CountsOccurrences(t *testing.T) {
+	k := keyAt(t, before, "List")
+	var b Baseline
+	b.Add(k, "List without options")
+	b.Add(k, "List without options")
+	if len(b.Entries) != 1 || b.Entries[0].Count != 2 {
+		t.Fatalf("expected a single entry with count 2, got %+v", b.Entries)
+	}
+
+	m := b.Matcher()
+	if !m.Match(k) || !m.Match(k) {
+		t.Fatalf("expected the first two occurrences to match")
+	}
+	if m.Match(k) {
+		t.Fatalf("expected a third occurrence to be new")
+	}
+}
+
+func TestWriteLoad_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	var b Baseline
+	b.Add(Key{Analyzer: "b", Package: "p", Fingerprint: "2"}, "second")
+	b.Add(Key{Analyzer: "a", Package: "p", Fingerprint: "1"}, "first")
+	if err := b.Write(path); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(got.Entries) != 2 || got.Entries[0].Analyzer != "a" {
+		t.Fatalf("unexpected entries after round trip: %+v", got.Entries)
+	}
+}
@@ -0,0 +1,149 @@
+// Package config loads the per-project .k8s-client-audit.yaml file that tunes
+// analyzer selection and thresholds without forking the linter.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	analyzers "github.com/amisstea/k8s-client-audit/internal/analyzers"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the configuration file looked up at the module root.
+const FileName = ".k8s-client-audit.yaml"
+
+// Config is the decoded configuration file.
+//
+//	analyzers:
+//	  experimental: false
+//	  enable: [noresync]
+//	  disable: [unstructuredeverywhere]
+//	hotPaths:
+//	  - example.com/operator/pkg/worker.Worker.Process
+//	thresholds:
+//	  qpsburst:
+//	    max-qps: 5000
+//	  largepages:
+//	    threshold: 500
+type Config struct {
+	Analyzers struct {
+		Experimental bool     `yaml:"experimental"`
+		Enable       []string `yaml:"enable"`
+		Disable      []string `yaml:"disable"`
+	} `yaml:"analyzers"`
+	// HotPaths lists functions treated like ServeHTTP and Reconcile by
+	// analyzers that look for work done on hot paths.
+	HotPaths []string `yaml:"hotPaths"`
+	// Thresholds maps analyzer names to values for their flags.
+	Thresholds map[string]map[string]string `yaml:"thresholds"`
+}
+
+// Find returns the path of the configuration file at the root of the module
+// containing dir, or "" if there is no such module or file.
+func Find(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			path := filepath.Join(dir, FileName)
+			if _, err := os.Stat(path); err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					return "", nil
+				}
+				return "", err
+			}
+			return path, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// Load reads and decodes the configuration file at path.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Parse decodes a configuration file. Unknown keys are rejected so that
+// misspelled settings do not silently fall back to defaults.
+func Parse(data []byte) (*Config, error) {
+	cfg := &Config{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Selection returns the analyzer selection described by the file.
+func (c *Config) Selection() analyzers.Selection {
+	return analyzers.Selection{
+		Experimental: c.Analyzers.Experimental,
+		Enable:       c.Analyzers.Enable,
+		Disable:      c.Analyzers.Disable,
+	}
+}
+
+// Apply sets the analyzer flags named by the thresholds and hot paths of c on
+// the analyzers in entries.
+func (c *Config) Apply(entries []analyzers.Entry) error {
+	byName := map[string]analyzers.Entry{}
+	for _, e := range entries {
+		byName[e.Name()] = e
+	}
+
+	names := make([]string, 0, len(c.Thresholds))
+	for name := range c.Thresholds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		e, ok := byName[name]
+		if !ok {
+			return fmt.Errorf("thresholds: unknown analyzer %q", name)
+		}
+		for flagName, value := range c.Thresholds[name] {
+			if e.Analyzer.Flags.Lookup(flagName) == nil {
+				return fmt.Errorf("thresholds: analyzer %s has no setting %q", name, flagName)
+			}
+			if err := e.Analyzer.Flags.Set(flagName, value); err != nil {
+				return fmt.Errorf("thresholds: %s.%s: %v", name, flagName, err)
+			}
+		}
+	}
+
+	if len(c.HotPaths) > 0 {
+		hotPaths := strings.Join(c.HotPaths, ",")
+		for _, e := range entries {
+			if e.Analyzer.Flags.Lookup("hot-paths") == nil {
+				continue
+			}
+			if err := e.Analyzer.Flags.Set("hot-paths", hotPaths); err != nil {
+				return fmt.Errorf("hotPaths: %v", err)
+			}
+		}
+	}
+	return nil
+}
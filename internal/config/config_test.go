@@ -0,0 +1,101 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	analyzers "github.com/amisstea/k8s-client-audit/internal/analyzers"
+)
+
+func TestFind_ModuleRoot(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "pkg", "controller")
+	os.MkdirAll(sub, 0o755)
+	os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/m\n"), 0o644)
+
+	path, err := Find(sub)
+	if err != nil || path != "" {
+		t.Fatalf("expected no config, got %q, %v", path, err)
+	}
+
+	want := filepath.Join(root, FileName)
+	os.WriteFile(want, []byte("hotPaths: [Process]\n"), 0o644)
+	path, err = Find(sub)
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if path != want {
+		t.Fatalf("expected %q, got %q", want, path)
+	}
+}
+
+func TestParse_Full(t *testing.T) {
+	cfg, err := Parse([]byte(`
+analyzers:
+  experimental: true
+  enable: [noresync]
+  disable: [listinloop]
+hotPaths:
+  - Worker.Process
+thresholds:
+  qpsburst:
+    max-qps: 5000
+`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	sel := cfg.Selection()
+	if !sel.Experimental || len(sel.Enable) != 1 || len(sel.Disable) != 1 {
+		t.Fatalf("unexpected selection: %+v", sel)
+	}
+	if got := cfg.Thresholds["qpsburst"]["max-qps"]; got != "5000" {
+		t.Fatalf("expected max-qps 5000, got %q", got)
+	}
+}
+
+func TestParse_UnknownKey(t *testing.T) {
+	if _, err := Parse([]byte("hotpath: [Process]\n")); err == nil {
+		t.Fatalf("expected error for unknown key")
+	}
+}
+
+func TestApply_SetsAnalyzerFlags(t *testing.T) {
+	cfg, err := Parse([]byte(`
+hotPaths: [Worker.Process]
+thresholds:
+  largepages:
+    threshold: 250
+`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	threshold := analyzers.AnalyzerLargePageSizes.Flags.Lookup("threshold")
+	hotPaths := analyzers.AnalyzerClientReuse.Flags.Lookup("hot-paths")
+	prevThreshold, prevHotPaths := threshold.Value.String(), hotPaths.Value.String()
+	t.Cleanup(func() {
+		threshold.Value.Set(prevThreshold)
+		hotPaths.Value.Set(prevHotPaths)
+	})
+
+	if err := cfg.Apply(analyzers.Registry); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if got := threshold.Value.String(); got != "250" {
+		t.Fatalf("expected threshold 250, got %q", got)
+	}
+	if got := hotPaths.Value.String(); got != "Worker.Process" {
+		t.Fatalf("expected hot paths to be set, got %q", got)
+	}
+}
+
+func TestApply_UnknownSetting(t *testing.T) {
+	cfg := &Config{Thresholds: map[string]map[string]string{"qpsburst": {"max-qsp": "1"}}}
+	if err := cfg.Apply(analyzers.Registry); err == nil {
+		t.Fatalf("expected error for unknown setting")
+	}
+	cfg = &Config{Thresholds: map[string]map[string]string{"nosuchanalyzer": {"x": "1"}}}
+	if err := cfg.Apply(analyzers.Registry); err == nil {
+		t.Fatalf("expected error for unknown analyzer")
+	}
+}
@@ -0,0 +1,137 @@
+// Package fix applies the suggested fixes attached to diagnostics.
+//
+// Only the first fix of each diagnostic is applied. Identical edits, such as
+// two fixes adding the same import, are merged; a fix that overlaps an edit
+// already accepted for the file is skipped as a whole so the result is never
+// a mix of two rewrites.
+//
+// This follows the -fix policy of the x/tools drivers, whose implementation
+// lives in go/analysis/internal/checker and cannot be imported; the public
+// go/analysis/checker package only runs analyzers. The linter also has to fix
+// after dropping suppressed, baselined and below-threshold diagnostics, which
+// multichecker.Main has no hook for.
+package fix
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"sort"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+type edit struct {
+	start, end int
+	text       string
+}
+
+// Result holds the rewritten files and the fixes that could not be applied.
+type Result struct {
+	// Files maps file names to their new content.
+	Files map[string][]byte
+	// Skipped lists the messages of fixes that conflicted with another fix.
+	Skipped []string
+}
+
+// Apply applies the first suggested fix of each diagnostic. read returns the
+// current content of a file. Rewritten files are gofmt'ed when they parse.
+func Apply(fset *token.FileSet, diags []analysis.Diagnostic, read func(filename string) ([]byte, error)) (*Result, error) {
+	accepted := map[string][]edit{}
+	res := &Result{Files: map[string][]byte{}}
+	for _, d := range diags {
+		if len(d.SuggestedFixes) == 0 {
+			continue
+		}
+		sf := d.SuggestedFixes[0]
+		byFile := map[string][]edit{}
+		for _, te := range sf.TextEdits {
+			end := te.End
+			if !end.IsValid() {
+				end = te.Pos
+			}
+			start, stop := fset.Position(te.Pos), fset.Position(end)
+			if start.Filename == "" || start.Filename != stop.Filename {
+				return nil, fmt.Errorf("%s: invalid edit in fix %q", fset.Position(d.Pos), sf.Message)
+			}
+			byFile[start.Filename] = append(byFile[start.Filename], edit{start.Offset, stop.Offset, string(te.NewText)})
+		}
+		if conflicts(accepted, byFile) {
+			res.Skipped = append(res.Skipped, fmt.Sprintf("%s: %s", fset.Position(d.Pos), sf.Message))
+			continue
+		}
+		for name, edits := range byFile {
+		next:
+			for _, e := range edits {
+				for _, a := range accepted[name] {
+					if a == e {
+						continue next
+					}
+				}
+				accepted[name] = append(accepted[name], e)
+			}
+		}
+	}
+
+	for name, edits := range accepted {
+		src, err := read(name)
+		if err != nil {
+			return nil, err
+		}
+		out, err := apply(src, edits)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if formatted, err := format.Source(out); err == nil {
+			out = formatted
+		}
+		res.Files[name] = out
+	}
+	return res, nil
+}
+
+// conflicts reports whether any edit in byFile overlaps a different accepted
+// edit to the same file.
+func conflicts(accepted, byFile map[string][]edit) bool {
+	for name, edits := range byFile {
+		for _, e := range edits {
+			for _, a := range accepted[name] {
+				if a != e && overlaps(a, e) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// overlaps reports whether two edits touch the same bytes. Insertions at the
+// same offset also overlap, as their relative order would be ambiguous.
+func overlaps(a, b edit) bool {
+	if a.start == a.end && b.start == b.end {
+		return a.start == b.start
+	}
+	return a.start < b.end && b.start < a.end || a.start == b.start
+}
+
+func apply(src []byte, edits []edit) ([]byte, error) {
+	sort.Slice(edits, func(i, j int) bool {
+		if edits[i].start != edits[j].start {
+			return edits[i].start < edits[j].start
+		}
+		return edits[i].end < edits[j].end
+	})
+	var buf bytes.Buffer
+	last := 0
+	for _, e := range edits {
+		if e.start < last || e.end > len(src) {
+			return nil, fmt.Errorf("edit at offset %d is out of range or overlapping", e.start)
+		}
+		buf.Write(src[last:e.start])
+		buf.WriteString(e.text)
+		last = e.end
+	}
+	buf.Write(src[last:])
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,55 @@
+package fix
+
+import (
+	"go/token"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+const src = `package a
+
+func f() { g(1); g(2) }
+`
+
+func setup() (*token.FileSet, *token.File, func(string) ([]byte, error)) {
+	fset := token.NewFileSet()
+	tf := fset.AddFile("a.go", -1, len(src))
+	tf.SetLinesForContent([]byte(src))
+	read := func(string) ([]byte, error) { return []byte(src), nil }
+	return fset, tf, read
+}
+
+func replace(tf *token.File, start, end int, text string) analysis.Diagnostic {
+	return analysis.Diagnostic{
+		Pos: tf.Pos(start),
+		SuggestedFixes: []analysis.SuggestedFix{{
+			Message:   "replace with " + text,
+			TextEdits: []analysis.TextEdit{{Pos: tf.Pos(start), End: tf.Pos(end), NewText: []byte(text)}},
+		}},
+	}
+}
+
+func TestApply_MergesAndSkipsConflicts(t *testing.T) {
+	fset, tf, read := setup()
+	one := len("package a\n\nfunc f() { g(")
+	two := len("package a\n\nfunc f() { g(1); g(")
+	diags := []analysis.Diagnostic{
+		replace(tf, one, one+1, "10"),
+		replace(tf, two, two+1, "20"),
+		replace(tf, one, one+1, "10"), // identical: merged
+		replace(tf, one, one+1, "99"), // conflicting: skipped
+		{Pos: tf.Pos(0)},              // no fix
+	}
+	res, err := Apply(fset, diags, read)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	want := "package a\n\nfunc f() { g(10); g(20) }\n"
+	if got := string(res.Files["a.go"]); got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if len(res.Skipped) != 1 {
+		t.Fatalf("expected one skipped fix, got %v", res.Skipped)
+	}
+}
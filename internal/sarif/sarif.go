@@ -0,0 +1,200 @@
+// Package sarif writes diagnostics in the Static Analysis Results Interchange
+// Format (SARIF) 2.1.0, the format accepted by GitHub code scanning and most
+// SARIF viewers.
+//
+// Only the subset of the schema needed to describe analyzer rules and their
+// results is modelled.
+package sarif
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// Version is the SARIF version written by this package.
+	Version = "2.1.0"
+	// Schema is the JSON schema of Version.
+	Schema = "https://json.schemastore.org/sarif-2.1.0.json"
+	// SrcRoot is the base id that relative artifact URIs are resolved against.
+	SrcRoot = "%SRCROOT%"
+)
+
+// Levels of a result.
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+	LevelNote    = "note"
+)
+
+// Log is the top-level SARIF object.
+type Log struct {
+	Version string `json:"version"`
+	Schema  string `json:"$schema"`
+	Runs    []*Run `json:"runs"`
+}
+
+// Run is a single invocation of an analysis tool.
+type Run struct {
+	Tool               Tool                        `json:"tool"`
+	OriginalURIBaseIDs map[string]ArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	Results            []Result                    `json:"results"`
+	ColumnKind         string                      `json:"columnKind,omitempty"`
+	rules              map[string]int
+}
+
+// Tool describes the analysis tool.
+type Tool struct {
+	Driver Driver `json:"driver"`
+}
+
+// Driver is the tool component that produced the results.
+type Driver struct {
+	Name           string `json:"name"`
+	Version        string `json:"version,omitempty"`
+	InformationURI string `json:"informationUri,omitempty"`
+	Rules          []Rule `json:"rules"`
+}
+
+// Rule describes an analyzer.
+type Rule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name,omitempty"`
+	ShortDescription     *Message           `json:"shortDescription,omitempty"`
+	FullDescription      *Message           `json:"fullDescription,omitempty"`
+	Help                 *Message           `json:"help,omitempty"`
+	HelpURI              string             `json:"helpUri,omitempty"`
+	DefaultConfiguration *RuleConfiguration `json:"defaultConfiguration,omitempty"`
+	Properties           map[string]any     `json:"properties,omitempty"`
+}
+
+// RuleConfiguration holds the default settings of a rule.
+type RuleConfiguration struct {
+	Level string `json:"level"`
+}
+
+// Message is a plain text message.
+type Message struct {
+	Text string `json:"text"`
+}
+
+// Result is a single finding.
+type Result struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level,omitempty"`
+	Message             Message           `json:"message"`
+	Locations           []Location        `json:"locations"`
+	RelatedLocations    []Location        `json:"relatedLocations,omitempty"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+}
+
+// Location is a place in a source file.
+type Location struct {
+	PhysicalLocation PhysicalLocation `json:"physicalLocation"`
+	Message          *Message         `json:"message,omitempty"`
+}
+
+// PhysicalLocation is a region of an artifact.
+type PhysicalLocation struct {
+	ArtifactLocation ArtifactLocation `json:"artifactLocation"`
+	Region           *Region          `json:"region,omitempty"`
+}
+
+// ArtifactLocation names a file, either relative to a base id or absolute.
+type ArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+// Region is a range of lines and columns. Columns count UTF-16 code units in
+// SARIF by default, so runs written by this package declare unicodeCodePoints;
+// the byte columns of go/token are close enough for ASCII sources.
+type Region struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+// NewRun returns a run for the named tool. Files under root are reported
+// relative to it.
+func NewRun(name, version, informationURI, root string) *Run {
+	r := &Run{
+		Tool:       Tool{Driver: Driver{Name: name, Version: version, InformationURI: informationURI, Rules: []Rule{}}},
+		Results:    []Result{},
+		ColumnKind: "unicodeCodePoints",
+		rules:      map[string]int{},
+	}
+	if root != "" {
+		r.OriginalURIBaseIDs = map[string]ArtifactLocation{
+			SrcRoot: {URI: fileURI(root) + "/"},
+		}
+	}
+	return r
+}
+
+// AddRule registers a rule. Adding a rule with an existing id is a no-op.
+func (r *Run) AddRule(rule Rule) {
+	if _, ok := r.rules[rule.ID]; ok {
+		return
+	}
+	r.rules[rule.ID] = len(r.Tool.Driver.Rules)
+	r.Tool.Driver.Rules = append(r.Tool.Driver.Rules, rule)
+}
+
+// AddResult appends res, filling in its rule index. The rule must have been
+// added first; results for unknown rules are dropped.
+func (r *Run) AddResult(res Result) {
+	i, ok := r.rules[res.RuleID]
+	if !ok {
+		return
+	}
+	res.RuleIndex = i
+	r.Results = append(r.Results, res)
+}
+
+// Write writes a log holding the runs to w.
+func Write(w io.Writer, runs ...*Run) error {
+	for _, r := range runs {
+		sort.SliceStable(r.Results, func(i, j int) bool {
+			a, b := r.Results[i].Locations[0].PhysicalLocation, r.Results[j].Locations[0].PhysicalLocation
+			if a.ArtifactLocation.URI != b.ArtifactLocation.URI {
+				return a.ArtifactLocation.URI < b.ArtifactLocation.URI
+			}
+			if a.Region == nil || b.Region == nil {
+				return false
+			}
+			if a.Region.StartLine != b.Region.StartLine {
+				return a.Region.StartLine < b.Region.StartLine
+			}
+			return a.Region.StartColumn < b.Region.StartColumn
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(Log{Version: Version, Schema: Schema, Runs: runs})
+}
+
+// Artifact returns the location of filename, relative to root when the file
+// lies beneath it.
+func Artifact(root, filename string) ArtifactLocation {
+	if root != "" {
+		if rel, err := filepath.Rel(root, filename); err == nil && !strings.HasPrefix(rel, "..") && !filepath.IsAbs(rel) {
+			return ArtifactLocation{URI: filepath.ToSlash(rel), URIBaseID: SrcRoot}
+		}
+	}
+	return ArtifactLocation{URI: fileURI(filename)}
+}
+
+func fileURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
@@ -0,0 +1,63 @@
+package sarif
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestRun_RuleIndexAndOrder(t *testing.T) {
+	r := NewRun("k8s-client-audit", "v1.0.0", "", "/src/repo")
+	r.AddRule(Rule{ID: "listinloop"})
+	r.AddRule(Rule{ID: "qpsburst"})
+	r.AddRule(Rule{ID: "listinloop"})
+
+	loc := func(file string, line int) []Location {
+		return []Location{{PhysicalLocation: PhysicalLocation{
+			ArtifactLocation: Artifact("/src/repo", file),
+			Region:           &Region{StartLine: line},
+		}}}
+	}
+	r.AddResult(Result{RuleID: "qpsburst", Message: Message{Text: "b"}, Locations: loc("/src/repo/b.go", 3)})
+	r.AddResult(Result{RuleID: "listinloop", Message: Message{Text: "a"}, Locations: loc("/src/repo/a.go", 7)})
+	r.AddResult(Result{RuleID: "unknown", Message: Message{Text: "dropped"}, Locations: loc("/src/repo/a.go", 1)})
+
+	var buf bytes.Buffer
+	if err := Write(&buf, r); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	var got Log
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got.Version != Version || len(got.Runs) != 1 {
+		t.Fatalf("unexpected log: %+v", got)
+	}
+	run := got.Runs[0]
+	if len(run.Tool.Driver.Rules) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(run.Tool.Driver.Rules))
+	}
+	if len(run.Results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(run.Results))
+	}
+	first := run.Results[0]
+	if first.RuleID != "listinloop" || first.RuleIndex != 0 {
+		t.Fatalf("unexpected first result: %+v", first)
+	}
+	if uri := first.Locations[0].PhysicalLocation.ArtifactLocation; uri.URI != "a.go" || uri.URIBaseID != SrcRoot {
+		t.Fatalf("unexpected artifact location: %+v", uri)
+	}
+	if run.Results[1].RuleIndex != 1 {
+		t.Fatalf("unexpected rule index for qpsburst: %d", run.Results[1].RuleIndex)
+	}
+	if base := run.OriginalURIBaseIDs[SrcRoot].URI; base != "file:///src/repo/" {
+		t.Fatalf("unexpected %s: %q", SrcRoot, base)
+	}
+}
+
+func TestArtifact_OutsideRoot(t *testing.T) {
+	got := Artifact("/src/repo", "/home/me/go/pkg/mod/x/y.go")
+	if got.URIBaseID != "" || got.URI != "file:///home/me/go/pkg/mod/x/y.go" {
+		t.Fatalf("unexpected artifact location: %+v", got)
+	}
+}
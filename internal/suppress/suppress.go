@@ -0,0 +1,231 @@
+// Package suppress implements //k8saudit:ignore directives, which silence
+// diagnostics that have been reviewed and accepted.
+//
+// A directive names one or more analyzers and must give a reason:
+//
+//	//k8saudit:ignore widenamespace,noselectors -- controller needs a cluster-wide view
+//
+// Its scope depends on where it is written:
+//   - before the package clause, it covers the whole file;
+//   - at the end of a line of code, it covers that line;
+//   - on a line of its own, it covers the statement or declaration that
+//     starts on the next line, including any block it opens.
+package suppress
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Prefix starts every suppression directive.
+const Prefix = "//k8saudit:ignore"
+
+// Scope describes the region of a file covered by a directive.
+type Scope string
+
+const (
+	ScopeLine  Scope = "line"
+	ScopeBlock Scope = "block"
+	ScopeFile  Scope = "file"
+)
+
+// Directive is a single parsed //k8saudit:ignore comment.
+type Directive struct {
+	Position  token.Position // start of the comment
+	Analyzers []string
+	Reason    string
+	Scope     Scope
+	// StartLine and EndLine are the inclusive range of lines covered.
+	StartLine, EndLine int
+
+	used map[string]bool
+}
+
+func (d *Directive) covers(analyzer string, pos token.Position) bool {
+	if pos.Filename != d.Position.Filename || pos.Line < d.StartLine || pos.Line > d.EndLine {
+		return false
+	}
+	for _, name := range d.Analyzers {
+		if name == analyzer {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse returns the directives found in f.
+func Parse(fset *token.FileSet, f *ast.File) []*Directive {
+	var out []*Directive
+	var lines *lineIndex
+	for _, cg := range f.Comments {
+		for _, c := range cg.List {
+			rest, ok := strings.CutPrefix(c.Text, Prefix)
+			if !ok || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+				continue
+			}
+			d := &Directive{Position: fset.Position(c.Pos()), used: map[string]bool{}}
+			names, reason, _ := strings.Cut(rest, "--")
+			d.Reason = strings.TrimSpace(reason)
+			for _, name := range strings.Split(names, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					d.Analyzers = append(d.Analyzers, name)
+				}
+			}
+
+			if lines == nil {
+				lines = indexLines(fset, f)
+			}
+			switch {
+			case c.End() < f.Package:
+				d.Scope, d.StartLine, d.EndLine = ScopeFile, 1, math.MaxInt
+			case lines.hasCodeBefore(d.Position):
+				d.Scope, d.StartLine, d.EndLine = ScopeLine, d.Position.Line, d.Position.Line
+			default:
+				next := fset.Position(cg.End()).Line + 1
+				end := lines.nodeEnd(next)
+				d.StartLine, d.EndLine = next, end
+				d.Scope = ScopeLine
+				if end > next {
+					d.Scope = ScopeBlock
+				}
+			}
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// lineIndex records, for each line of a file, where syntax nodes begin and
+// how far the outermost node starting on that line extends.
+type lineIndex struct {
+	firstCol map[int]int // smallest column at which a node starts or ends
+	startCol map[int]int // column of the leftmost node starting on the line
+	endLine  map[int]int // last line of the outermost node starting on the line
+}
+
+func indexLines(fset *token.FileSet, f *ast.File) *lineIndex {
+	li := &lineIndex{firstCol: map[int]int{}, startCol: map[int]int{}, endLine: map[int]int{}}
+	note := func(p token.Position) {
+		if c, ok := li.firstCol[p.Line]; !ok || p.Column < c {
+			li.firstCol[p.Line] = p.Column
+		}
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n.(type) {
+		case nil, *ast.File, *ast.Comment, *ast.CommentGroup:
+			return true
+		}
+		start, end := fset.Position(n.Pos()), fset.Position(n.End()-1)
+		note(start)
+		note(end)
+		c, ok := li.startCol[start.Line]
+		switch {
+		case !ok || start.Column < c:
+			li.startCol[start.Line] = start.Column
+			li.endLine[start.Line] = end.Line
+		case start.Column == c && end.Line > li.endLine[start.Line]:
+			li.endLine[start.Line] = end.Line
+		}
+		return true
+	})
+	return li
+}
+
+func (li *lineIndex) hasCodeBefore(p token.Position) bool {
+	c, ok := li.firstCol[p.Line]
+	return ok && c < p.Column
+}
+
+// nodeEnd returns the last line of the outermost node starting on line, or
+// line itself when no node starts there.
+func (li *lineIndex) nodeEnd(line int) int {
+	if end, ok := li.endLine[line]; ok {
+		return end
+	}
+	return line
+}
+
+// Index holds the directives of a set of files and records which of them
+// suppressed a diagnostic.
+type Index struct {
+	seen       map[string]bool
+	directives []*Directive
+}
+
+// NewIndex returns an empty index.
+func NewIndex() *Index {
+	return &Index{seen: map[string]bool{}}
+}
+
+// AddFile parses the directives of f. Files already added, for example as
+// part of a test variant of the same package, are skipped.
+func (ix *Index) AddFile(fset *token.FileSet, f *ast.File) {
+	name := fset.File(f.Pos()).Name()
+	if ix.seen[name] {
+		return
+	}
+	ix.seen[name] = true
+	ix.directives = append(ix.directives, Parse(fset, f)...)
+}
+
+// Suppresses reports whether a diagnostic of analyzer at pos is covered by a
+// directive. Directives without a reason do not suppress anything.
+func (ix *Index) Suppresses(analyzer string, pos token.Position) bool {
+	suppressed := false
+	for _, d := range ix.directives {
+		if d.Reason != "" && d.covers(analyzer, pos) {
+			d.used[analyzer] = true
+			suppressed = true
+		}
+	}
+	return suppressed
+}
+
+// Problem is a directive that should be fixed or removed.
+type Problem struct {
+	Position token.Position
+	Message  string
+}
+
+// Problems returns directives that lack a reason, name unknown analyzers, or
+// no longer suppress any diagnostic, sorted by position. known reports
+// whether an analyzer exists; ran reports whether it was part of this run,
+// as unused directives can only be judged for analyzers that ran.
+func (ix *Index) Problems(known, ran func(analyzer string) bool) []Problem {
+	var out []Problem
+	add := func(d *Directive, format string, args ...any) {
+		out = append(out, Problem{Position: d.Position, Message: fmt.Sprintf(format, args...)})
+	}
+	for _, d := range ix.directives {
+		if len(d.Analyzers) == 0 {
+			add(d, "k8saudit:ignore directive names no analyzer")
+			continue
+		}
+		if d.Reason == "" {
+			add(d, "k8saudit:ignore directive has no reason; append \"-- <reason>\"")
+		}
+		for _, name := range d.Analyzers {
+			switch {
+			case !known(name):
+				add(d, "k8saudit:ignore names unknown analyzer %q", name)
+			case d.Reason != "" && ran(name) && !d.used[name]:
+				add(d, "k8saudit:ignore %s does not match any diagnostic; remove it", name)
+			}
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i].Position, out[j].Position
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return out
+}
@@ -0,0 +1,111 @@
+package suppress
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+const src = `package a
+
+func f(c interface{ List() error }) {
+	_ = c.List() //k8saudit:ignore listinloop -- bounded to three items
+
+	//k8saudit:ignore listinloop,noselectors -- startup only
+	for i := 0; i < 3; i++ {
+		_ = c.List()
+	}
+
+	_ = c.List()
+
+	//k8saudit:ignore noselectors
+	_ = c.List()
+}
+`
+
+func newIndex(t *testing.T, src string) (*Index, *token.FileSet) {
+	t.Helper()
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "a.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	ix := NewIndex()
+	ix.AddFile(fset, f)
+	return ix, fset
+}
+
+func at(line int) token.Position {
+	return token.Position{Filename: "a.go", Line: line, Column: 1}
+}
+
+func TestParse_Scopes(t *testing.T) {
+	ix, _ := newIndex(t, src)
+	if len(ix.directives) != 3 {
+		t.Fatalf("expected 3 directives, got %d", len(ix.directives))
+	}
+	line, block, noReason := ix.directives[0], ix.directives[1], ix.directives[2]
+	if line.Scope != ScopeLine || line.StartLine != 4 || line.EndLine != 4 {
+		t.Fatalf("unexpected trailing directive: %+v", line)
+	}
+	if block.Scope != ScopeBlock || block.StartLine != 7 || block.EndLine != 9 {
+		t.Fatalf("unexpected block directive: %+v", block)
+	}
+	if len(block.Analyzers) != 2 || block.Reason != "startup only" {
+		t.Fatalf("unexpected block directive fields: %+v", block)
+	}
+	if noReason.Scope != ScopeLine || noReason.StartLine != 14 || noReason.Reason != "" {
+		t.Fatalf("unexpected directive without reason: %+v", noReason)
+	}
+}
+
+func TestSuppresses(t *testing.T) {
+	ix, _ := newIndex(t, src)
+	cases := []struct {
+		analyzer string
+		line     int
+		want     bool
+	}{
+		{"listinloop", 4, true},
+		{"noselectors", 4, false},
+		{"listinloop", 8, true},
+		{"noselectors", 8, true},
+		{"listinloop", 11, false},
+		{"noselectors", 14, false}, // no reason, so not honored
+	}
+	for _, c := range cases {
+		if got := ix.Suppresses(c.analyzer, at(c.line)); got != c.want {
+			t.Errorf("Suppresses(%s, line %d) = %v, want %v", c.analyzer, c.line, got, c.want)
+		}
+	}
+}
+
+func TestFileScope(t *testing.T) {
+	ix, _ := newIndex(t, `//k8saudit:ignore widenamespace -- tooling for cluster admins
+
+package a
+
+func f() {}
+`)
+	if !ix.Suppresses("widenamespace", at(5)) {
+		t.Fatalf("expected file-scoped directive to cover the whole file")
+	}
+}
+
+func TestProblems(t *testing.T) {
+	ix, _ := newIndex(t, src+"\n//k8saudit:ignore nosuchanalyzer -- typo\nvar _ = 1\n")
+	ix.Suppresses("listinloop", at(4))
+	known := func(name string) bool { return name != "nosuchanalyzer" }
+	ran := func(name string) bool { return name == "listinloop" || name == "noselectors" }
+
+	problems := ix.Problems(known, ran)
+	want := []int{6, 6, 13, 17}
+	if len(problems) != len(want) {
+		t.Fatalf("expected %d problems, got %d: %+v", len(want), len(problems), problems)
+	}
+	for i, line := range want {
+		if problems[i].Position.Line != line {
+			t.Errorf("problem %d at line %d, want %d: %s", i, problems[i].Position.Line, line, problems[i].Message)
+		}
+	}
+}